@@ -17,8 +17,18 @@ import (
 	"syscall"
 	"time"
 
+	"github.com/keploy/keploy-review-agent/internal/analyzer"
 	"github.com/keploy/keploy-review-agent/internal/api"
+	"github.com/keploy/keploy-review-agent/internal/baseline"
 	"github.com/keploy/keploy-review-agent/internal/config"
+	"github.com/keploy/keploy-review-agent/internal/jobs"
+)
+
+// Exit codes of the review command.
+const (
+	exitPassed = 0
+	exitFailed = 1
+	exitError  = 2
 )
 
 type PullRequest struct {
@@ -148,10 +158,153 @@ func startServer(wg *sync.WaitGroup) {
 
 
 
+}
+
+// configFileEnv names the environment variable holding the path of the
+// server configuration file. Without it, configuration is read from the
+// environment.
+const configFileEnv = "KEPLOY_REVIEW_CONFIG"
+
+// configWatchInterval is how often the configuration file is checked for
+// changes.
+const configWatchInterval = 5 * time.Second
+
+// runConfig implements "config validate <file>", which reports every
+// problem in a server configuration file.
+func runConfig(args []string) int {
+	if len(args) < 2 || args[0] != "validate" {
+		log.Printf("Usage: %s config validate <config-file-path>", os.Args[0])
+		return exitError
+	}
+
+	if _, err := config.LoadFile(args[1]); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return exitFailed
+	}
+	fmt.Printf("%s is valid\n", args[1])
+	return exitPassed
+}
+
+// withGitHubToken uses the token given to a command instead of the
+// configured one.
+func withGitHubToken(token string) func(*config.Config) {
+	return func(cfg *config.Config) {
+		cfg.GitHubToken = token
+	}
+}
+
+// runReview analyzes a single pull request without starting the server and
+// returns an exit code reflecting the quality gate verdict.
+func runReview(args []string) int {
+	if len(args) < 2 {
+		log.Printf("Usage: %s review <github-token> <pull-request-url>", os.Args[0])
+		return exitError
+	}
+
+	cfg, err := config.LoadFrom(os.Getenv(configFileEnv), withGitHubToken(args[0]))
+	if err != nil {
+		log.Printf("Failed to load configuration: %v", err)
+		return exitError
+	}
+
+	owner, repo, err := extractOwnerAndRepo(args[1])
+	if err != nil {
+		log.Printf("Error extracting owner and repo: %v", err)
+		return exitError
+	}
+	prNumber, err := strconv.Atoi(extractPullNumber(args[1]))
+	if err != nil {
+		log.Printf("failed to convert pull number to integer: %v", err)
+		return exitError
+	}
+
+	result, err := analyzer.NewOrchestrator(cfg).AnalyzeCode(&analyzer.Job{
+		Provider:  "github",
+		RepoOwner: owner,
+		RepoName:  repo,
+		PRNumber:  prNumber,
+	})
+	if err != nil {
+		log.Printf("Review failed: %v", err)
+		return exitError
+	}
+
+	if !result.Verdict.Passed {
+		log.Printf("Quality gate failed: %s", strings.Join(result.Verdict.Reasons, "; "))
+		return exitFailed
+	}
+	return exitPassed
+}
+
+// runBaseline implements "baseline <github-token> <owner/repo> [ref]
+// [output-file]", which scans a ref, the default branch by default, and
+// writes its findings to a baseline file to commit to the repository.
+func runBaseline(args []string) int {
+	if len(args) < 2 {
+		log.Printf("Usage: %s baseline <github-token> <owner/repo> [ref] [output-file]", os.Args[0])
+		return exitError
+	}
+	owner, repo, ok := strings.Cut(args[1], "/")
+	if !ok || owner == "" || repo == "" || strings.Contains(repo, "/") {
+		log.Printf("Invalid repository %q, expected owner/repo", args[1])
+		return exitError
+	}
+	var ref string
+	output := baseline.FileName
+	if len(args) > 2 {
+		ref = args[2]
+	}
+	if len(args) > 3 {
+		output = args[3]
+	}
+
+	cfg, err := config.LoadFrom(os.Getenv(configFileEnv), withGitHubToken(args[0]))
+	if err != nil {
+		log.Printf("Failed to load configuration: %v", err)
+		return exitError
+	}
+
+	b, err := analyzer.NewOrchestrator(cfg).Baseline(owner, repo, ref)
+	if err != nil {
+		log.Printf("Baseline failed: %v", err)
+		return exitError
+	}
+	if err := b.Write(output); err != nil {
+		log.Printf("%v", err)
+		return exitError
+	}
+	fmt.Printf("Wrote %d findings of %s/%s at %s (%s) to %s\n", len(b.Entries), owner, repo, b.Ref, b.Commit, output)
+	return exitPassed
+}
+
+// reloadOnSIGHUP reloads the configuration every time the process receives
+// SIGHUP. Invalid configurations are logged and the current one is kept.
+func reloadOnSIGHUP(store *config.Store) {
+	hup := make(chan os.Signal, 1)
+	signal.Notify(hup, syscall.SIGHUP)
+	for range hup {
+		snapshot, err := store.Reload()
+		if err != nil {
+			log.Printf("Configuration reload failed, keeping version %d: %v", snapshot.Version, err)
+			continue
+		}
+		log.Printf("Configuration reloaded (version %d)", snapshot.Version)
+	}
 }
 
 func main() {
 
+	if len(os.Args) > 1 {
+		switch os.Args[1] {
+		case "review":
+			os.Exit(runReview(os.Args[2:]))
+		case "config":
+			os.Exit(runConfig(os.Args[2:]))
+		case "baseline":
+			os.Exit(runBaseline(os.Args[2:]))
+		}
+	}
+
 	if len(os.Args) < 3 {
 		log.Fatalf("Usage: %s <config-file-path>", os.Args[0])
 	}
@@ -164,17 +317,24 @@ func main() {
 	PullRequest_URL := os.Args[2]
 
 	err = os.Setenv("PULL_REQUEST_URL", PullRequest_URL)
-	cfg, err := config.Load()
+	configPath := os.Getenv(configFileEnv)
+	cfg, err := config.LoadFrom(configPath)
 	if err != nil {
 		log.Fatalf("Failed to load configuration: %v", err)
 	}
+	store := config.NewStore(configPath, cfg)
+
+	watchCtx, stopWatch := context.WithCancel(context.Background())
+	defer stopWatch()
+	go store.Watch(watchCtx, configWatchInterval)
+	go reloadOnSIGHUP(store)
 
 	var wg sync.WaitGroup
 
 	wg.Add(1)
 	go startServer(&wg)
 
-	router := api.NewRouter(cfg)
+	router := api.NewRouter(store, jobs.NewRegistry())
 
 	server := &http.Server{
 		Addr:         ":" + cfg.ServerPort,
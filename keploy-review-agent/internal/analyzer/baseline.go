@@ -0,0 +1,182 @@
+package analyzer
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io/fs"
+	"log"
+	"os"
+	"path/filepath"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/keploy/keploy-review-agent/internal/analyzer/static"
+	"github.com/keploy/keploy-review-agent/internal/baseline"
+	"github.com/keploy/keploy-review-agent/internal/config"
+	"github.com/keploy/keploy-review-agent/internal/repoconfig"
+	"github.com/keploy/keploy-review-agent/pkg/github"
+	"github.com/keploy/keploy-review-agent/pkg/models"
+)
+
+// Baseline scans every file of a repository at ref, the default branch when
+// ref is empty, and returns its findings as a baseline. The scan uses the
+// repository configuration at ref and reviews whole files, like `/review
+// full`. Scanning a repository takes much longer than a review, so it has
+// no deadline.
+func (o *Orchestrator) Baseline(owner, repo, ref string) (*baseline.Baseline, error) {
+	ctx := context.Background()
+
+	if ref == "" {
+		branch, err := o.githubClient.GetDefaultBranch(ctx, owner, repo)
+		if err != nil {
+			return nil, fmt.Errorf("failed to find the default branch: %w", err)
+		}
+		ref = branch
+	}
+	sha, err := o.githubClient.ResolveCommit(ctx, owner, repo, ref)
+	if err != nil {
+		return nil, fmt.Errorf("failed to resolve %s: %w", ref, err)
+	}
+
+	job := &Job{Provider: "github", RepoOwner: owner, RepoName: repo, HeadSHA: sha, BaseSHA: sha, Full: true}
+	settings := o.loadSettings(ctx, job)
+
+	// The scan reads the whole tree, so it always checks the repository
+	// out, with the tarball API when checkouts are disabled for reviews.
+	checkout := o.newCheckout(job)
+	if checkout.mode == config.CheckoutOff {
+		checkout.mode = config.CheckoutTarball
+	}
+	defer checkout.close()
+
+	files, err := o.fetchTree(ctx, checkout, settings)
+	if err != nil {
+		return nil, err
+	}
+	log.Printf("Scanning %d files of %s/%s at %s (%s)", len(files), owner, repo, ref, sha)
+
+	issues, gaps := o.collectIssues(ctx, job, files, settings, checkout)
+	if gaps != "" {
+		log.Printf("Warning: Some files could not be analyzed; the baseline misses their findings")
+	}
+	return baseline.New(ref, sha, issues), nil
+}
+
+// fetchTree reads the text files of the checkout that the review settings
+// do not exclude. The baseline file itself is never scanned.
+func (o *Orchestrator) fetchTree(ctx context.Context, checkout *checkout, settings *repoconfig.Settings) ([]*models.File, error) {
+	dir := checkout.dir(ctx)
+	if dir == "" {
+		return nil, fmt.Errorf("failed to check out the repository: %w", checkout.err)
+	}
+
+	var candidates []*models.File
+	err := filepath.WalkDir(dir, func(path string, entry fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if entry.IsDir() && entry.Name() == ".git" {
+			return filepath.SkipDir
+		}
+		if !entry.Type().IsRegular() {
+			return nil // directories and links
+		}
+		info, err := entry.Info()
+		if err != nil {
+			return err
+		}
+		rel, err := filepath.Rel(dir, path)
+		if err != nil {
+			return err
+		}
+		rel = filepath.ToSlash(rel)
+		if info.Size() > o.cfg.MaxFileSizeBytes || rel == settings.BaselineFile {
+			return nil
+		}
+		candidates = append(candidates, &models.File{Path: rel})
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list files: %w", err)
+	}
+
+	var files []*models.File
+	for _, file := range filterFiles(candidates, settings) {
+		content, err := os.ReadFile(filepath.Join(dir, filepath.FromSlash(file.Path)))
+		if err != nil {
+			return nil, fmt.Errorf("failed to read %s: %w", file.Path, err)
+		}
+		if bytes.IndexByte(content, 0) >= 0 || !utf8.Valid(content) {
+			continue // binary
+		}
+		file.Content = string(content)
+		files = append(files, file)
+	}
+	return files, nil
+}
+
+// applyBaseline drops the issues grandfathered by the repository baseline
+// and returns a note for the review summary on how many were reported and
+// how many have been fixed.
+func (o *Orchestrator) applyBaseline(ctx context.Context, job *Job, files []*models.File, settings *repoconfig.Settings,
+	issues []*models.Issue) ([]*models.Issue, string) {
+	b := o.loadBaseline(ctx, job, settings)
+	if b == nil {
+		return issues, ""
+	}
+
+	paths := make([]string, len(files))
+	for i, file := range files {
+		paths[i] = file.Path
+	}
+	// A baselined finding only counts as fixed when its analyzer looked at
+	// the whole file again; the LLM and the type-check report only the
+	// changed lines by default.
+	unverified := unverifiedRules(settings, job, o.aiAnalyzer != nil)
+	if !job.Full {
+		unverified = append(unverified, "ai")
+	}
+	changedOnly := typeCheckChangedOnly(o.cfg, job)
+	recheck := func(rule string) bool {
+		if changedOnly && static.IsTypeScriptCode(rule) {
+			return false
+		}
+		for _, prefix := range unverified {
+			if prefix == "*" || rule == prefix || strings.HasPrefix(rule, prefix+"/") {
+				return false
+			}
+		}
+		return true
+	}
+
+	kept, result := b.Apply(issues, paths, recheck)
+	log.Printf("Baseline: %d findings suppressed, %d fixed", result.Suppressed, result.Fixed)
+	if note := result.String(); note != "" {
+		return kept, "\n\n**Baseline:** " + note + "."
+	}
+	return kept, ""
+}
+
+// loadBaseline reads the baseline from the base branch of a pull request, so
+// that a pull request cannot grandfather its own findings. It returns nil
+// when the repository has no valid baseline.
+func (o *Orchestrator) loadBaseline(ctx context.Context, job *Job, settings *repoconfig.Settings) *baseline.Baseline {
+	if settings.BaselineFile == "" || job.Provider != "github" || job.BaseSHA == "" {
+		return nil
+	}
+
+	content, err := o.githubClient.GetFileContent(ctx, job.RepoOwner, job.RepoName, settings.BaselineFile, job.BaseSHA)
+	if err != nil {
+		if !github.IsNotFound(err) {
+			log.Printf("Warning: Failed to read %s: %v", settings.BaselineFile, err)
+		}
+		return nil
+	}
+	b, err := baseline.Parse([]byte(content))
+	if err != nil {
+		log.Printf("Warning: Ignoring %s: %v", settings.BaselineFile, err)
+		return nil
+	}
+	return b
+}
@@ -0,0 +1,106 @@
+package analyzer
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"log"
+
+	"github.com/keploy/keploy-review-agent/internal/cache"
+	"github.com/keploy/keploy-review-agent/pkg/models"
+)
+
+// cacheSpec describes how the results of an analyzer are cached.
+type cacheSpec struct {
+	analyzer string
+	version  string // changes whenever the analyzer settings or prompt change
+	model    string
+
+	// fileVersion, when set, adds per-file inputs besides the content to
+	// the version, such as the diff sent to the LLM.
+	fileVersion func(*models.File) string
+}
+
+// partialFailure is implemented by analyzer errors that affect only some
+// files; the results of the other files are still valid.
+type partialFailure interface {
+	FailedPaths() []string
+}
+
+// versionOf hashes the given settings into a cache version.
+func versionOf(settings ...interface{}) string {
+	data, err := json.Marshal(settings)
+	if err != nil {
+		data = []byte(fmt.Sprintf("%#v", settings))
+	}
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:8])
+}
+
+func (o *Orchestrator) cacheKey(file *models.File, spec *cacheSpec) cache.Key {
+	version := spec.version
+	if spec.fileVersion != nil {
+		version += ":" + spec.fileVersion(file)
+	}
+	return cache.Key{
+		BlobSHA:  file.BlobSHA(),
+		Path:     file.Path,
+		Analyzer: spec.analyzer,
+		Version:  version,
+		Model:    spec.model,
+	}
+}
+
+// cachedIssues returns the cached issues of the files and the files that
+// still need to be analyzed.
+func (o *Orchestrator) cachedIssues(files []*models.File, spec *cacheSpec) ([]*models.Issue, []*models.File) {
+	if o.cache == nil || spec == nil {
+		return nil, files
+	}
+
+	var issues []*models.Issue
+	var missing []*models.File
+	for _, file := range files {
+		var cached []*models.Issue
+		if o.cache.Get(o.cacheKey(file, spec), &cached) {
+			issues = append(issues, cached...)
+			continue
+		}
+		missing = append(missing, file)
+	}
+	return issues, missing
+}
+
+// storeIssues caches the issues of every analyzed file, including files
+// without issues, except the files whose analysis failed. Results that
+// cannot be attributed to the analyzed files are not cached at all.
+func (o *Orchestrator) storeIssues(files []*models.File, spec *cacheSpec, issues []*models.Issue, failed []string) {
+	if o.cache == nil || spec == nil {
+		return
+	}
+
+	byPath := make(map[string][]*models.Issue, len(files))
+	for _, file := range files {
+		byPath[file.Path] = []*models.Issue{}
+	}
+	for _, issue := range issues {
+		if _, ok := byPath[issue.Path]; !ok {
+			return
+		}
+		byPath[issue.Path] = append(byPath[issue.Path], issue)
+	}
+	for _, path := range failed {
+		delete(byPath, path)
+	}
+
+	for _, file := range files {
+		fileIssues, ok := byPath[file.Path]
+		if !ok {
+			continue
+		}
+		if err := o.cache.Put(o.cacheKey(file, spec), fileIssues); err != nil {
+			log.Printf("Warning: Failed to cache %s results for %s: %v", spec.analyzer, file.Path, err)
+		}
+	}
+}
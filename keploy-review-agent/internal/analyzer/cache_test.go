@@ -0,0 +1,35 @@
+package analyzer
+
+import (
+	"testing"
+	"time"
+
+	"github.com/keploy/keploy-review-agent/internal/cache"
+	"github.com/keploy/keploy-review-agent/pkg/models"
+)
+
+func TestCachedIssuesSameBlob(t *testing.T) {
+	c, err := cache.Open(t.TempDir(), time.Hour, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	o := &Orchestrator{cache: c}
+	spec := &cacheSpec{analyzer: "static", version: "1"}
+
+	const content = "package util\n\nfunc Do() {}\n"
+	a := &models.File{Path: "a/util.go", Content: content}
+	b := &models.File{Path: "b/util.go", Content: content}
+	if a.BlobSHA() != b.BlobSHA() {
+		t.Fatal("files with the same content have different blob SHAs")
+	}
+
+	o.storeIssues([]*models.File{a}, spec, []*models.Issue{{Path: a.Path, Line: 3, Severity: models.SeverityWarning, Description: "exported function without comment"}}, nil)
+
+	issues, missing := o.cachedIssues([]*models.File{a, b}, spec)
+	if len(issues) != 1 || issues[0].Path != a.Path {
+		t.Errorf("cachedIssues() = %v, want the issue of %s", issues, a.Path)
+	}
+	if len(missing) != 1 || missing[0] != b {
+		t.Errorf("cachedIssues() missing = %v, want %s", missing, b.Path)
+	}
+}
@@ -0,0 +1,64 @@
+package analyzer
+
+import (
+	"context"
+	"errors"
+	"log"
+	"sync"
+
+	"github.com/keploy/keploy-review-agent/internal/config"
+	"github.com/keploy/keploy-review-agent/internal/workspace"
+)
+
+// checkout is the checkout of the head of a job, made the first time an
+// analyzer asks for it and shared by every analyzer of the review.
+type checkout struct {
+	o    *Orchestrator
+	job  *Job
+	mode string // config.CheckoutAuto, CheckoutGit, CheckoutTarball or CheckoutOff
+
+	once sync.Once
+	ws   *workspace.Workspace
+	err  error
+}
+
+func (o *Orchestrator) newCheckout(job *Job) *checkout {
+	return &checkout{o: o, job: job, mode: o.cfg.StaticAnalysisConfig.Checkout}
+}
+
+// enabled reports whether the job can be checked out at all.
+func (c *checkout) enabled() bool {
+	return c.job.Provider == "github" && c.job.HeadSHA != "" && c.mode != config.CheckoutOff
+}
+
+// dir returns the root of the checkout, or "" when the repository could not
+// be checked out and analyzers have to make do with the changed files.
+func (c *checkout) dir(ctx context.Context) string {
+	c.once.Do(func() {
+		if !c.enabled() {
+			c.err = errors.New("checkouts are disabled")
+			return
+		}
+		ws, err := workspace.Checkout(ctx, c.o.githubClient, c.job.RepoOwner, c.job.RepoName, c.job.HeadSHA, c.mode)
+		if err != nil {
+			c.err = err
+			log.Printf("Warning: Analyzing changed files only, checkout of %s/%s failed: %v", c.job.RepoOwner, c.job.RepoName, err)
+			return
+		}
+		log.Printf("Checked out %s/%s at %s with %s", c.job.RepoOwner, c.job.RepoName, c.job.HeadSHA, ws.Method)
+		c.ws = ws
+	})
+	if c.ws == nil {
+		return ""
+	}
+	return c.ws.Dir
+}
+
+// close removes the checkout, if one was made.
+func (c *checkout) close() {
+	if c.ws != nil {
+		if err := c.ws.Close(); err != nil {
+			log.Printf("Warning: Failed to remove checkout: %v", err)
+		}
+	}
+}
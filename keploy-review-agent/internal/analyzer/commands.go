@@ -0,0 +1,132 @@
+package analyzer
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"strings"
+	"time"
+
+	"github.com/keploy/keploy-review-agent/internal/command"
+	"github.com/keploy/keploy-review-agent/internal/formatter"
+)
+
+// PermissionError is returned when a developer runs a command their
+// repository role does not allow.
+type PermissionError struct {
+	User       string
+	Permission string
+	Required   string
+}
+
+func (e *PermissionError) Error() string {
+	return fmt.Sprintf("%s has %s permission, %s is required", e.User, e.Permission, e.Required)
+}
+
+// RunCommand runs a slash command posted in the pull request conversation,
+// after checking the repository role of its author.
+func (o *Orchestrator) RunCommand(event *CommentEvent, cmd *command.Command) error {
+	ctx, cancel := context.WithTimeout(
+		context.Background(),
+		time.Duration(o.cfg.MaxProcessingTime)*time.Second,
+	)
+	defer cancel()
+
+	permission, err := o.githubClient.GetPermission(ctx, event.RepoOwner, event.RepoName, event.Author)
+	if err != nil {
+		return err
+	}
+	if !cmd.Allowed(permission) {
+		o.replyToCommand(ctx, event, fmt.Sprintf("`%s` needs %s access to this repository.", cmd.Text, cmd.Permission()))
+		return &PermissionError{User: event.Author, Permission: permission, Required: cmd.Permission()}
+	}
+
+	job := o.commentJob(ctx, event)
+	switch cmd.Name {
+	case command.Review:
+		job.Full = cmd.Full
+		job.SkipLLM = cmd.SkipLLM
+		_, err = o.AnalyzeCode(job)
+		return err
+	case command.Explain:
+		return o.explain(ctx, event, job, cmd.Path, cmd.Line)
+	case command.Ignore:
+		body := fmt.Sprintf("`%s` findings will not be reported again on this pull request. Run `/review` to update the review status.\n%s",
+			cmd.Rule, formatter.IgnoredRuleMarker(cmd.Rule))
+		log.Printf("Ignoring rule %s on %s/%s PR #%d at the request of %s",
+			cmd.Rule, event.RepoOwner, event.RepoName, event.PRNumber, event.Author)
+		return o.replyToCommand(ctx, event, body)
+	case command.Summary:
+		return o.summarize(ctx, job)
+	}
+	return fmt.Errorf("unknown command %q", cmd.Name)
+}
+
+// ReportCommandError tells the author of a comment that their command could
+// not be parsed.
+func (o *Orchestrator) ReportCommandError(event *CommentEvent, cmdErr error) error {
+	ctx, cancel := context.WithTimeout(context.Background(), time.Minute)
+	defer cancel()
+	return o.replyToCommand(ctx, event, fmt.Sprintf("%v\n\n%s", cmdErr, command.Usage))
+}
+
+func (o *Orchestrator) replyToCommand(ctx context.Context, event *CommentEvent, body string) error {
+	return o.githubClient.CreateIssueComment(ctx, event.RepoOwner, event.RepoName, event.PRNumber,
+		fmt.Sprintf("@%s %s", event.Author, body))
+}
+
+// explain answers /explain with the findings posted at the line, if any, and
+// the code around it.
+func (o *Orchestrator) explain(ctx context.Context, event *CommentEvent, job *Job, path string, line int) error {
+	if o.aiAnalyzer == nil {
+		return fmt.Errorf("cannot explain code: no LLM provider is available")
+	}
+	if job.HeadSHA == "" {
+		return fmt.Errorf("cannot explain code: the pull request head is unknown")
+	}
+
+	content, err := o.githubClient.GetFileContent(ctx, job.RepoOwner, job.RepoName, path, job.HeadSHA)
+	if err != nil {
+		o.replyToCommand(ctx, event, fmt.Sprintf("I could not read `%s` at the head of this pull request.", path))
+		return fmt.Errorf("failed to read %s: %w", path, err)
+	}
+	if n := strings.Count(strings.TrimRight(content, "\n"), "\n") + 1; line > n {
+		return o.replyToCommand(ctx, event, fmt.Sprintf("`%s` has only %d lines.", path, n))
+	}
+
+	comments, err := o.githubClient.ListReviewComments(ctx, job.RepoOwner, job.RepoName, job.PRNumber)
+	if err != nil {
+		log.Printf("Warning: Failed to fetch review comments: %v", err)
+	}
+	var findings []string
+	for _, comment := range comments {
+		if comment.Path == path && comment.Line == line && len(formatter.ExtractFingerprints(comment.Body)) > 0 {
+			findings = append(findings, stripMarkers(comment.Body))
+		}
+	}
+	if len(findings) == 0 {
+		findings = []string{fmt.Sprintf("(no findings were reported at %s:%d)", path, line)}
+	}
+
+	question := fmt.Sprintf("Explain what the code at %s:%d does and any problems with it.", path, line)
+	answer, err := o.aiAnalyzerFor(o.loadSettings(ctx, job)).Answer(ctx, strings.Join(findings, "\n\n"),
+		numberedLines(content, line, codeContextLines), nil, question)
+	if err != nil {
+		return fmt.Errorf("failed to explain code: %w", err)
+	}
+	return o.replyToCommand(ctx, event, fmt.Sprintf("**`%s:%d`**\n\n%s", path, line, answer))
+}
+
+// summarize posts the walkthrough of the pull request on request, even when
+// walkthroughs are not enabled for every review.
+func (o *Orchestrator) summarize(ctx context.Context, job *Job) error {
+	if o.aiAnalyzer == nil {
+		return fmt.Errorf("cannot summarize: no LLM provider is available")
+	}
+	settings := o.loadSettings(ctx, job)
+	files, err := o.fetchChangedFiles(ctx, job)
+	if err != nil {
+		return fmt.Errorf("failed to fetch changed files: %w", err)
+	}
+	return o.postWalkthrough(ctx, job, filterFiles(files, settings), settings)
+}
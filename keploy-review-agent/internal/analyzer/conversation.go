@@ -0,0 +1,240 @@
+package analyzer
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/keploy/keploy-review-agent/internal/command"
+	"github.com/keploy/keploy-review-agent/internal/formatter"
+	"github.com/keploy/keploy-review-agent/pkg/github"
+)
+
+// codeContextLines is the number of lines sent around a finding when a
+// developer asks about it.
+const codeContextLines = 15
+
+// maintainerPermission is the repository role whose judgement of findings
+// counts: dismissing them, and the feedback the agent learns from.
+const maintainerPermission = "triage"
+
+var (
+	// dismissalRegex matches replies telling the agent that a finding is not
+	// a problem.
+	dismissalRegex = regexp.MustCompile(`(?i)\b(intentional(ly)?|by design|false positive|not an? (issue|problem|bug)|won'?t fix|wontfix|ignore (this|it)|dismiss(ed)?|expected behaviou?r|as expected)\b`)
+
+	htmlCommentRegex = regexp.MustCompile(`(?s)<!--.*?-->`)
+)
+
+// CommentEvent is a comment a developer posted on a pull request.
+type CommentEvent struct {
+	RepoOwner string
+	RepoName  string
+	PRNumber  int
+	ID        int64
+	InReplyTo int64 // root comment of the thread, for replies to inline comments
+	Review    bool  // an inline review comment rather than a conversation comment
+	Author    string
+	Body      string
+}
+
+// HandleComment responds to comments addressed to the agent. Replies in the
+// thread of a finding either dismiss it, which suppresses its fingerprint on
+// later reviews and needs triage access, or ask about it, which is answered
+// with the LLM. Mentions
+// in the pull request conversation are answered with the list of findings
+// as context.
+func (o *Orchestrator) HandleComment(event *CommentEvent) error {
+	ctx, cancel := context.WithTimeout(
+		context.Background(),
+		time.Duration(o.cfg.MaxProcessingTime)*time.Second,
+	)
+	defer cancel()
+
+	if event.Review && event.InReplyTo != 0 {
+		return o.handleThreadReply(ctx, event)
+	}
+	if o.mentioned(event.Body) {
+		return o.answerConversation(ctx, event)
+	}
+	return nil
+}
+
+func (o *Orchestrator) handleThreadReply(ctx context.Context, event *CommentEvent) error {
+	root, err := o.githubClient.GetReviewComment(ctx, event.RepoOwner, event.RepoName, event.InReplyTo)
+	if err != nil {
+		return fmt.Errorf("failed to fetch review comment: %w", err)
+	}
+	fingerprints := formatter.ExtractFingerprints(root.Body)
+	if len(fingerprints) == 0 {
+		return nil // not a thread on one of our findings
+	}
+
+	if o.isDismissal(event.Body) {
+		permission, err := o.githubClient.GetPermission(ctx, event.RepoOwner, event.RepoName, event.Author)
+		if err != nil {
+			return err
+		}
+		if !command.HasPermission(permission, maintainerPermission) {
+			body := fmt.Sprintf("@%s dismissing a finding needs %s access to this repository.", event.Author, maintainerPermission)
+			if err := o.githubClient.ReplyToReviewComment(ctx, event.RepoOwner, event.RepoName, event.PRNumber, root.ID, body); err != nil {
+				return err
+			}
+			return &PermissionError{User: event.Author, Permission: permission, Required: maintainerPermission}
+		}
+
+		var body strings.Builder
+		fmt.Fprintf(&body, "Thanks @%s, this finding will not be reported again on this pull request.", event.Author)
+		for _, fp := range fingerprints {
+			body.WriteString("\n" + formatter.SuppressionMarker(fp))
+		}
+		log.Printf("Suppressing %s on %s/%s PR #%d, dismissed by %s",
+			strings.Join(fingerprints, ", "), event.RepoOwner, event.RepoName, event.PRNumber, event.Author)
+		return o.githubClient.ReplyToReviewComment(ctx, event.RepoOwner, event.RepoName, event.PRNumber, root.ID, body.String())
+	}
+
+	if !o.mentioned(event.Body) && !strings.Contains(event.Body, "?") {
+		return nil
+	}
+	if o.aiAnalyzer == nil {
+		return fmt.Errorf("cannot answer questions: no LLM provider is available")
+	}
+
+	job := o.commentJob(ctx, event)
+	code := o.findingContext(ctx, job, root)
+	thread, err := o.threadReplies(ctx, event, root.ID)
+	if err != nil {
+		log.Printf("Warning: Failed to fetch earlier replies: %v", err)
+	}
+
+	settings := o.loadSettings(ctx, job)
+	answer, err := o.aiAnalyzerFor(settings).Answer(ctx, stripMarkers(root.Body), code, thread, o.stripMention(event.Body))
+	if err != nil {
+		return fmt.Errorf("failed to answer question: %w", err)
+	}
+	return o.githubClient.ReplyToReviewComment(ctx, event.RepoOwner, event.RepoName, event.PRNumber, root.ID, answer)
+}
+
+// answerConversation answers a mention in the pull request conversation,
+// where there is no single finding to discuss.
+func (o *Orchestrator) answerConversation(ctx context.Context, event *CommentEvent) error {
+	if o.isDismissal(event.Body) {
+		body := fmt.Sprintf("@%s to dismiss a finding, reply to its review comment so I know which one you mean.", event.Author)
+		return o.githubClient.CreateIssueComment(ctx, event.RepoOwner, event.RepoName, event.PRNumber, body)
+	}
+	if o.aiAnalyzer == nil {
+		return fmt.Errorf("cannot answer questions: no LLM provider is available")
+	}
+
+	comments, err := o.githubClient.ListReviewComments(ctx, event.RepoOwner, event.RepoName, event.PRNumber)
+	if err != nil {
+		return fmt.Errorf("failed to fetch review comments: %w", err)
+	}
+	var findings []string
+	for _, comment := range comments {
+		if len(formatter.ExtractFingerprints(comment.Body)) == 0 {
+			continue
+		}
+		title := strings.SplitN(stripMarkers(comment.Body), "\n", 2)[0]
+		findings = append(findings, fmt.Sprintf("- %s:%d %s", comment.Path, comment.Line, title))
+	}
+	if len(findings) == 0 {
+		findings = []string{"(no findings were reported on this pull request)"}
+	}
+
+	settings := o.loadSettings(ctx, o.commentJob(ctx, event))
+	answer, err := o.aiAnalyzerFor(settings).Answer(ctx, strings.Join(findings, "\n"), "", nil, o.stripMention(event.Body))
+	if err != nil {
+		return fmt.Errorf("failed to answer question: %w", err)
+	}
+	return o.githubClient.CreateIssueComment(ctx, event.RepoOwner, event.RepoName, event.PRNumber,
+		fmt.Sprintf("@%s %s", event.Author, answer))
+}
+
+// commentJob returns the job of the pull request a comment was posted on,
+// so the repository configuration and head revision can be read.
+func (o *Orchestrator) commentJob(ctx context.Context, event *CommentEvent) *Job {
+	job := &Job{Provider: "github", RepoOwner: event.RepoOwner, RepoName: event.RepoName, PRNumber: event.PRNumber}
+	if pr, err := o.githubClient.GetPullRequest(ctx, event.RepoOwner, event.RepoName, event.PRNumber); err != nil {
+		log.Printf("Warning: Failed to fetch pull request details: %v", err)
+	} else {
+		job.HeadSHA = pr.Head.Sha
+		job.BaseSHA = pr.Base.Sha
+		job.Fork = pr.FromFork()
+	}
+	return job
+}
+
+// findingContext returns the numbered lines of the head revision around the
+// finding, falling back to the diff hunk of the comment.
+func (o *Orchestrator) findingContext(ctx context.Context, job *Job, root *github.Comment) string {
+	if job.HeadSHA == "" || root.Path == "" || root.Line <= 0 {
+		return root.DiffHunk
+	}
+	content, err := o.githubClient.GetFileContent(ctx, job.RepoOwner, job.RepoName, root.Path, job.HeadSHA)
+	if err != nil {
+		log.Printf("Warning: Failed to read %s: %v", root.Path, err)
+		return root.DiffHunk
+	}
+	return numberedLines(content, root.Line, codeContextLines)
+}
+
+// threadReplies returns the earlier replies of a thread as "author: body"
+// entries, oldest first, excluding the reply being answered.
+func (o *Orchestrator) threadReplies(ctx context.Context, event *CommentEvent, rootID int64) ([]string, error) {
+	comments, err := o.githubClient.ListReviewComments(ctx, event.RepoOwner, event.RepoName, event.PRNumber)
+	if err != nil {
+		return nil, err
+	}
+	var thread []string
+	for _, comment := range comments {
+		if comment.InReplyToID != rootID || comment.ID == event.ID {
+			continue
+		}
+		thread = append(thread, fmt.Sprintf("%s: %s", comment.User.Login, stripMarkers(comment.Body)))
+	}
+	return thread, nil
+}
+
+// isDismissal reports whether a comment addressed to the agent says that a
+// finding is not a problem. Questions, such as "is this a false positive?",
+// are answered rather than taken as dismissals.
+func (o *Orchestrator) isDismissal(body string) bool {
+	if !o.mentioned(body) || strings.HasSuffix(stripMarkers(o.stripMention(body)), "?") {
+		return false
+	}
+	return dismissalRegex.MatchString(body)
+}
+
+func (o *Orchestrator) mentioned(body string) bool {
+	return strings.Contains(strings.ToLower(body), "@"+strings.ToLower(o.cfg.GitHubBotLogin))
+}
+
+func (o *Orchestrator) stripMention(body string) string {
+	mention := regexp.MustCompile(`(?i)@` + regexp.QuoteMeta(o.cfg.GitHubBotLogin) + `\b`)
+	return strings.TrimSpace(mention.ReplaceAllString(body, ""))
+}
+
+func stripMarkers(body string) string {
+	return strings.TrimSpace(htmlCommentRegex.ReplaceAllString(body, ""))
+}
+
+func numberedLines(content string, line, radius int) string {
+	lines := strings.Split(strings.TrimRight(content, "\n"), "\n")
+	start, end := line-radius, line+radius
+	if start < 1 {
+		start = 1
+	}
+	if end > len(lines) {
+		end = len(lines)
+	}
+
+	var b strings.Builder
+	for n := start; n <= end; n++ {
+		fmt.Fprintf(&b, "%6d | %s\n", n, lines[n-1])
+	}
+	return b.String()
+}
@@ -2,13 +2,47 @@ package custom
 
 import (
 	"context"
-	
+	"fmt"
+	"regexp"
+	"strings"
+
 	"github.com/keploy/keploy-review-agent/internal/config"
+	"github.com/keploy/keploy-review-agent/internal/glob"
 	"github.com/keploy/keploy-review-agent/pkg/models"
 )
 
+// Rule is a repository-defined check that flags every line matching a
+// regular expression.
+type Rule struct {
+	ID         string          `yaml:"id"`
+	Pattern    string          `yaml:"pattern"`
+	Message    string          `yaml:"message"`
+	Severity   models.Severity `yaml:"severity"`
+	Paths      []string        `yaml:"paths"`
+	Suggestion string          `yaml:"suggestion"`
+
+	re *regexp.Regexp
+}
+
+// Compile validates the rule and prepares its pattern for matching.
+func (r *Rule) Compile() error {
+	if r.ID == "" {
+		return fmt.Errorf("custom rule is missing an id")
+	}
+	if r.Pattern == "" {
+		return fmt.Errorf("custom rule %q is missing a pattern", r.ID)
+	}
+	re, err := regexp.Compile(r.Pattern)
+	if err != nil {
+		return fmt.Errorf("custom rule %q has an invalid pattern: %w", r.ID, err)
+	}
+	r.re = re
+	return nil
+}
+
 type Rules struct {
-	cfg *config.Config
+	cfg   *config.Config
+	rules []Rule
 }
 
 func NewRules(cfg *config.Config) *Rules {
@@ -17,10 +51,68 @@ func NewRules(cfg *config.Config) *Rules {
 	}
 }
 
+// WithRules returns a copy of the analyzer that checks the given rules.
+func (r *Rules) WithRules(rules []Rule) *Rules {
+	return &Rules{
+		cfg:   r.cfg,
+		rules: rules,
+	}
+}
+
 func (r *Rules) Analyze(ctx context.Context, files []*models.File) ([]*models.Issue, error) {
 	var issues []*models.Issue
 
+	for i := range r.rules {
+		rule := &r.rules[i]
+		if rule.re == nil {
+			if err := rule.Compile(); err != nil {
+				return nil, err
+			}
+		}
+
+		for _, file := range files {
+			if len(rule.Paths) > 0 && !glob.MatchAny(rule.Paths, file.Path) {
+				continue
+			}
+			issues = append(issues, rule.check(file)...)
+		}
+	}
 
-	
 	return issues, nil
 }
+
+func (r *Rule) check(file *models.File) []*models.Issue {
+	var issues []*models.Issue
+
+	severity := r.Severity
+	if severity == 0 {
+		severity = models.SeverityWarning
+	}
+	message := r.Message
+	if message == "" {
+		message = fmt.Sprintf("Line matches the pattern of custom rule %s", r.ID)
+	}
+
+	for i, line := range strings.Split(file.Content, "\n") {
+		loc := r.re.FindStringIndex(line)
+		if loc == nil {
+			continue
+		}
+		issues = append(issues, &models.Issue{
+			Path:        file.Path,
+			Line:        i + 1,
+			Column:      loc[0] + 1,
+			EndLine:     i + 1,
+			EndColumn:   loc[1] + 1,
+			Severity:    severity,
+			RuleID:      "custom/" + r.ID,
+			Category:    "custom",
+			Title:       fmt.Sprintf("Custom rule: %s", r.ID),
+			Description: message,
+			Suggestion:  r.Suggestion,
+			Source:      "Custom Rules",
+		})
+	}
+
+	return issues
+}
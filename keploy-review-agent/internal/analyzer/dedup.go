@@ -0,0 +1,161 @@
+package analyzer
+
+import (
+	"strings"
+
+	"github.com/keploy/keploy-review-agent/pkg/models"
+)
+
+const (
+	// Issues further apart than this are never considered the same problem.
+	dedupLineDistance = 1
+	// Minimum word overlap between two messages to treat them as duplicates.
+	dedupSimilarity = 0.5
+)
+
+// assignFingerprints sets the fingerprint and initial source list of every
+// issue, using the content of the file the issue belongs to. Identical
+// findings on identical lines are told apart by their occurrence in the
+// file, so that each can be posted and dismissed on its own; reports of one
+// finding on the same line keep one fingerprint.
+func assignFingerprints(issues []*models.Issue, files []*models.File) {
+	contents := make(map[string]string, len(files))
+	for _, file := range files {
+		contents[file.Path] = file.Content
+	}
+
+	lines := make(map[string][]int) // distinct lines by fingerprint
+	for _, issue := range issues {
+		issue.Fingerprint = models.Fingerprint(issue, contents[issue.Path])
+		if len(issue.Sources) == 0 {
+			issue.Sources = []string{issue.Source}
+		}
+		if !containsInt(lines[issue.Fingerprint], issue.Line) {
+			lines[issue.Fingerprint] = append(lines[issue.Fingerprint], issue.Line)
+		}
+	}
+
+	for _, issue := range issues {
+		found := lines[issue.Fingerprint]
+		if len(found) < 2 {
+			continue
+		}
+		n := 0
+		for _, line := range found {
+			if line < issue.Line {
+				n++
+			}
+		}
+		issue.Fingerprint = models.Occurrence(issue.Fingerprint, n)
+	}
+}
+
+func containsInt(values []int, v int) bool {
+	for _, value := range values {
+		if value == v {
+			return true
+		}
+	}
+	return false
+}
+
+// mergeDuplicates collapses issues that describe the same problem. Issues with
+// the same fingerprint are always merged; issues reported by different sources
+// are merged when they point at (nearly) the same line and their messages are
+// similar enough. The most severe report is kept and lists all of the sources.
+func mergeDuplicates(issues []*models.Issue) []*models.Issue {
+	var merged []*models.Issue
+
+	for _, issue := range issues {
+		var target *models.Issue
+		for _, existing := range merged {
+			if isDuplicate(existing, issue) {
+				target = existing
+				break
+			}
+		}
+
+		if target == nil {
+			merged = append(merged, issue)
+			continue
+		}
+
+		if issue.Severity > target.Severity {
+			issue.Sources = appendSources(issue.Sources, target.Sources)
+			if issue.Suggestion == "" {
+				issue.Suggestion = target.Suggestion
+			}
+			*target = *issue
+		} else {
+			target.Sources = appendSources(target.Sources, issue.Sources)
+			if target.Suggestion == "" {
+				target.Suggestion = issue.Suggestion
+			}
+		}
+	}
+
+	return merged
+}
+
+func isDuplicate(a, b *models.Issue) bool {
+	if a.Fingerprint != "" && a.Fingerprint == b.Fingerprint {
+		return true
+	}
+	if a.Path != b.Path || a.Source == b.Source {
+		return false
+	}
+	if abs(a.Line-b.Line) > dedupLineDistance {
+		return false
+	}
+	return similarity(a.Description, b.Description) >= dedupSimilarity
+}
+
+// similarity returns the Jaccard index of the words of two messages.
+func similarity(a, b string) float64 {
+	wordsA := words(a)
+	wordsB := words(b)
+	if len(wordsA) == 0 || len(wordsB) == 0 {
+		return 0
+	}
+
+	common := 0
+	for w := range wordsA {
+		if wordsB[w] {
+			common++
+		}
+	}
+	return float64(common) / float64(len(wordsA)+len(wordsB)-common)
+}
+
+func words(msg string) map[string]bool {
+	set := make(map[string]bool)
+	for _, w := range strings.FieldsFunc(models.NormalizeMessage(msg), func(r rune) bool {
+		return !(r >= 'a' && r <= 'z' || r >= '0' && r <= '9' || r == '_')
+	}) {
+		set[w] = true
+	}
+	return set
+}
+
+func appendSources(dst, src []string) []string {
+	for _, s := range src {
+		found := false
+		for _, d := range dst {
+			if d == s {
+				found = true
+				break
+			}
+		}
+		if !found {
+			dst = append(dst, s)
+		}
+	}
+	return dst
+}
+
+func abs(n int) int {
+	if n < 0 {
+		return -n
+	}
+	return n
+}
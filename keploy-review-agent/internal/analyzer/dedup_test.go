@@ -0,0 +1,43 @@
+package analyzer
+
+import (
+	"testing"
+
+	"github.com/keploy/keploy-review-agent/pkg/models"
+)
+
+func TestAssignFingerprints(t *testing.T) {
+	const content = "package main\n" +
+		"if err != nil {\n" + // 2
+		"\treturn err\n" +
+		"}\n" +
+		"if err != nil {\n" + // 5
+		"\treturn err\n" +
+		"}\n"
+	files := []*models.File{{Path: "main.go", Content: content}}
+	issue := func(line int, source string) *models.Issue {
+		return &models.Issue{Path: "main.go", Line: line, RuleID: "wrapcheck", Source: source, Description: "error returned unwrapped"}
+	}
+
+	issues := []*models.Issue{issue(5, "golangci-lint"), issue(2, "golangci-lint"), issue(2, "golangci-lint"), issue(3, "golangci-lint")}
+	assignFingerprints(issues, files)
+
+	first, second, again, other := issues[1], issues[0], issues[2], issues[3]
+	if first.Fingerprint != models.Fingerprint(first, content) {
+		t.Errorf("first occurrence fingerprint = %s, want the plain fingerprint", first.Fingerprint)
+	}
+	if second.Fingerprint == first.Fingerprint {
+		t.Errorf("identical findings on identical lines share fingerprint %s", first.Fingerprint)
+	}
+	if again.Fingerprint != first.Fingerprint {
+		t.Errorf("reports of one finding on one line have fingerprints %s and %s", first.Fingerprint, again.Fingerprint)
+	}
+	if other.Fingerprint != models.Fingerprint(other, content) {
+		t.Errorf("unique finding fingerprint = %s, want the plain fingerprint", other.Fingerprint)
+	}
+
+	merged := mergeDuplicates(issues)
+	if len(merged) != 3 {
+		t.Errorf("mergeDuplicates() kept %d issues, want 3", len(merged))
+	}
+}
@@ -4,6 +4,7 @@ import (
 	"context"
 	"encoding/json"
 	"fmt"
+	"log"
 	"net/http"
 	"net/url"
 	"path/filepath"
@@ -11,6 +12,7 @@ import (
 	"time"
 
 	"github.com/keploy/keploy-review-agent/internal/config"
+	"github.com/keploy/keploy-review-agent/internal/diff"
 	"github.com/keploy/keploy-review-agent/pkg/models"
 )
 
@@ -45,13 +47,13 @@ func (s *Scanner) Analyze(ctx context.Context, files []*models.File) ([]*models.
 			fmt.Println("Detected Go module file.")
 			deps := parseGoMod(file.Content)
 			fmt.Println("Parsed dependencies:", deps)
-			issues = append(issues, s.checkDeps(ctx, "go", deps)...)
+			issues = append(issues, s.checkDeps(ctx, file, "go", deps)...)
 
 		case "package.json":
 			fmt.Println("Detected package.json file.")
 			deps := parsePackageJSON(file.Content)
 			fmt.Println("Parsed dependencies:", deps)
-			issues = append(issues, s.checkDeps(ctx, "npm", deps)...)
+			issues = append(issues, s.checkDeps(ctx, file, "npm", deps)...)
 
 		default:
 			fmt.Println("Skipping file:", file.Path)
@@ -65,12 +67,13 @@ func (s *Scanner) Analyze(ctx context.Context, files []*models.File) ([]*models.
 	return issues, nil
 }
 
-func (s *Scanner) checkDeps(ctx context.Context, ecosystem string, deps map[string]string) []*models.Issue {
+func (s *Scanner) checkDeps(ctx context.Context, file *models.File, ecosystem string, deps map[string]string) []*models.Issue {
 	fmt.Println("********************************************************************************")
 	fmt.Printf("Checking dependencies for ecosystem: %s\n", ecosystem)
 	fmt.Println("********************************************************************************")
 
 	var issues []*models.Issue
+	added := addedLines(file)
 
 	for pkg, version := range deps {
 		cleanVersion := strings.TrimLeft(version, "^~") // Remove ^ and ~
@@ -143,12 +146,25 @@ func (s *Scanner) checkDeps(ctx context.Context, ecosystem string, deps map[stri
 			title := advDetail["title"].(string)
 			fmt.Printf("Found vulnerability in %s: %s (CVSS: %.1f)\n", pkg, title, cvssScore)
 
-			if cvssScore >= 7.0 {
+			if cvssScore > 0 && cvssScore >= s.cfg.DependencyMinCVSS {
+				docURL, _ := advDetail["url"].(string)
+				if docURL == "" {
+					docURL = "https://osv.dev/vulnerability/" + advisoryID
+				}
+
+				line := findDependencyLine(file.Content, pkg)
 				issue := &models.Issue{
-					Path:        pkg,
-					Title:       "Vulnerable Dependency",
+					Path:        file.Path,
+					Line:        line,
+					RuleID:      advisoryID,
+					Category:    "dependency",
+					Title:       fmt.Sprintf("Vulnerable Dependency: %s@%s", pkg, cleanVersion),
 					Description: fmt.Sprintf("%s (CVSS: %.1f)", title, cvssScore),
-					Severity:    models.SeverityError,
+					Severity:    cvssSeverity(cvssScore),
+					Tags:        advisoryAliases(advDetail),
+					CVSS:        cvssScore,
+					NewDep:      added == nil || added[line],
+					DocURL:      docURL,
 					Source:      "deps.dev",
 				}
 				issues = append(issues, issue)
@@ -168,6 +184,61 @@ func (s *Scanner) checkDeps(ctx context.Context, ecosystem string, deps map[stri
 	return issues
 }
 
+// addedLines returns the lines of a manifest added by its diff, or nil when
+// the diff is unknown and every dependency counts as new.
+func addedLines(file *models.File) map[int]bool {
+	if file.Patch == "" {
+		return nil
+	}
+	hunks, err := diff.Parse(file.Patch)
+	if err != nil {
+		log.Printf("Warning: Failed to parse the diff of %s, treating its dependencies as new: %v", file.Path, err)
+		return nil
+	}
+	return diff.AddedLines(hunks)
+}
+
+// cvssSeverity maps a CVSS v3 score to a severity using the qualitative
+// rating scale of the specification.
+func cvssSeverity(score float64) models.Severity {
+	switch {
+	case score >= 9.0:
+		return models.SeverityCritical
+	case score >= 7.0:
+		return models.SeverityError
+	case score >= 4.0:
+		return models.SeverityWarning
+	default:
+		return models.SeverityInfo
+	}
+}
+
+// advisoryAliases returns the CVE and other identifiers an advisory is known by.
+func advisoryAliases(advDetail map[string]interface{}) []string {
+	var aliases []string
+	raw, _ := advDetail["aliases"].([]interface{})
+	for _, a := range raw {
+		if alias, ok := a.(string); ok {
+			aliases = append(aliases, alias)
+		}
+	}
+	return aliases
+}
+
+// findDependencyLine returns the first manifest line mentioning the package,
+// or 0 when it cannot be located.
+func findDependencyLine(content, pkg string) int {
+	lines := strings.Split(content, "\n")
+	for _, needle := range []string{`"` + pkg + `"`, pkg + " "} {
+		for i, line := range lines {
+			if strings.Contains(line, needle) {
+				return i + 1
+			}
+		}
+	}
+	return 0
+}
+
 func parseGoMod(content string) map[string]string {
 	fmt.Println("********************************************************************************")
 	fmt.Println("Parsing go.mod file content...")
@@ -0,0 +1,220 @@
+package analyzer
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/keploy/keploy-review-agent/internal/command"
+	"github.com/keploy/keploy-review-agent/internal/feedback"
+	"github.com/keploy/keploy-review-agent/internal/formatter"
+	"github.com/keploy/keploy-review-agent/pkg/github"
+	"github.com/keploy/keploy-review-agent/pkg/models"
+)
+
+// tuning holds the rules demoted or muted for a repository because
+// reviewers dismissed too many of their findings.
+type tuning struct {
+	decisions map[string]feedback.Decision
+	affected  map[string]int // issues demoted or muted in this review, by rule
+}
+
+// learnFeedback records the reactions and resolutions of the findings posted
+// on the pull request and returns the resulting rule adjustments. Only the
+// signals of maintainers count, so contributors cannot mute the rules that
+// flag their own changes. A thread resolved while its finding is still
+// reported counts as a dismissal; one resolved after the finding disappeared
+// counts as a fix. Conflicting signals on a finding are settled by their
+// precedence, and findings older than the feedback window are pruned.
+func (o *Orchestrator) learnFeedback(ctx context.Context, job *Job, history *reviewHistory, issues []*models.Issue) *tuning {
+	t := &tuning{affected: make(map[string]int)}
+	if o.feedbackStore == nil || job.Provider != "github" {
+		return t
+	}
+
+	isMaintainer := o.maintainers(ctx, job)
+	thumbsUp := make(map[string]bool)
+	thumbsDown := make(map[string]bool)
+	for _, comment := range history.reacted {
+		reactions, err := o.githubClient.ListReactions(ctx, job.RepoOwner, job.RepoName, comment.id, comment.review)
+		if err != nil {
+			log.Printf("Warning: Failed to fetch reactions: %v", err)
+			continue
+		}
+		for _, reaction := range reactions {
+			if reaction.User.Type == "Bot" || !isMaintainer(reaction.User.Login) {
+				continue
+			}
+			for _, fp := range comment.fingerprints {
+				switch reaction.Content {
+				case "+1":
+					thumbsUp[fp] = true
+				case "-1":
+					thumbsDown[fp] = true
+				}
+			}
+		}
+	}
+
+	threads, err := o.githubClient.ListReviewThreads(ctx, job.RepoOwner, job.RepoName, job.PRNumber)
+	if err != nil {
+		log.Printf("Warning: Failed to fetch review threads: %v", err)
+	}
+	var resolved []*github.ReviewThread
+	for _, thread := range threads {
+		if thread.Resolved && isMaintainer(thread.ResolvedBy) {
+			resolved = append(resolved, thread)
+		}
+	}
+	current := make(map[string]*models.Issue, len(issues))
+	for _, issue := range issues {
+		current[issue.Fingerprint] = issue
+	}
+
+	// Every finding gets the strongest of its signals, recorded once.
+	signals := make(map[string]string)
+	signal := func(fp, s string) {
+		if current, ok := signals[fp]; !ok || feedback.Stronger(s, current) {
+			signals[fp] = s
+		}
+	}
+	for fp := range thumbsUp {
+		signal(fp, feedback.SignalThumbsUp)
+	}
+	for _, thread := range resolved {
+		for _, fp := range formatter.ExtractFingerprints(thread.RootBody) {
+			if _, ok := current[fp]; ok {
+				signal(fp, feedback.SignalResolved)
+			} else {
+				signal(fp, feedback.SignalFixed)
+			}
+		}
+	}
+	for fp := range thumbsDown {
+		signal(fp, feedback.SignalThumbsDown)
+	}
+	for fp := range history.suppressed {
+		signal(fp, feedback.SignalDismissed)
+	}
+
+	policy := o.feedbackPolicy()
+	now := time.Now()
+	repo, err := o.feedbackStore.Update(job.RepoOwner+"/"+job.RepoName, func(r *feedback.Repo) {
+		r.Prune(policy.Since(now))
+		for fp, s := range signals {
+			rule := ""
+			if issue, ok := current[fp]; ok {
+				rule = issue.RuleID
+			} else if f, ok := r.Findings[fp]; ok {
+				rule = f.Rule
+			}
+			r.Record(fp, rule, feedback.SignalOutcome(s), s)
+		}
+	})
+	if err != nil {
+		log.Printf("Warning: Failed to record review feedback: %v", err)
+		return t
+	}
+
+	t.decisions = policy.Decide(repo.Stats(policy.Since(now)))
+	return t
+}
+
+func (o *Orchestrator) feedbackPolicy() feedback.Policy {
+	return feedback.Policy{
+		MinSamples: o.cfg.FeedbackMinSamples,
+		DemoteRate: o.cfg.FeedbackDemoteRate,
+		MuteRate:   o.cfg.FeedbackMuteRate,
+		WindowDays: o.cfg.FeedbackWindowDays,
+	}
+}
+
+// maintainers returns a function reporting whether a user has a maintainer
+// role in the repository of the job, looking each user up once.
+func (o *Orchestrator) maintainers(ctx context.Context, job *Job) func(login string) bool {
+	known := make(map[string]bool)
+	return func(login string) bool {
+		if login == "" {
+			return false
+		}
+		if maintainer, ok := known[login]; ok {
+			return maintainer
+		}
+		permission, err := o.githubClient.GetPermission(ctx, job.RepoOwner, job.RepoName, login)
+		if err != nil {
+			log.Printf("Warning: Ignoring the feedback of %s: %v", login, err)
+		}
+		known[login] = err == nil && command.HasPermission(permission, maintainerPermission)
+		return known[login]
+	}
+}
+
+// recordPosted records the findings about to be posted, so later feedback
+// on them can be attributed to their rule.
+func (o *Orchestrator) recordPosted(job *Job, comments []*models.ReviewComment, issues []*models.Issue) {
+	if o.feedbackStore == nil || len(comments) == 0 {
+		return
+	}
+	rules := make(map[string]string, len(issues))
+	for _, issue := range issues {
+		rules[issue.Fingerprint] = issue.RuleID
+	}
+	since := o.feedbackPolicy().Since(time.Now())
+	_, err := o.feedbackStore.Update(job.RepoOwner+"/"+job.RepoName, func(r *feedback.Repo) {
+		r.Prune(since)
+		for _, comment := range comments {
+			r.Record(comment.Fingerprint, rules[comment.Fingerprint], feedback.OutcomeOpen, "")
+		}
+	})
+	if err != nil {
+		log.Printf("Warning: Failed to record posted findings: %v", err)
+	}
+}
+
+// apply drops the issues of muted rules and lowers the severity of the
+// issues of demoted rules by one level.
+func (t *tuning) apply(issues []*models.Issue) []*models.Issue {
+	if len(t.decisions) == 0 {
+		return issues
+	}
+	var kept []*models.Issue
+	for _, issue := range issues {
+		decision, ok := t.decisions[issue.RuleID]
+		switch {
+		case !ok:
+		case decision.Action == feedback.ActionMute:
+			t.affected[issue.RuleID]++
+			continue
+		case issue.Severity > models.SeverityHint:
+			issue.Severity--
+			t.affected[issue.RuleID]++
+		}
+		kept = append(kept, issue)
+	}
+	return kept
+}
+
+// report describes the adjusted rules for the review summary.
+func (t *tuning) report() string {
+	if len(t.decisions) == 0 {
+		return ""
+	}
+	rules := make([]string, 0, len(t.decisions))
+	for rule := range t.decisions {
+		rules = append(rules, rule)
+	}
+	sort.Strings(rules)
+
+	var b strings.Builder
+	b.WriteString("\n\n**Rules adjusted from reviewer feedback:**")
+	for _, rule := range rules {
+		fmt.Fprintf(&b, "\n- %s", t.decisions[rule])
+		if n := t.affected[rule]; n > 0 {
+			fmt.Fprintf(&b, "; %d issues affected in this review", n)
+		}
+	}
+	return b.String()
+}
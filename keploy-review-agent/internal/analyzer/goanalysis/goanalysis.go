@@ -0,0 +1,357 @@
+package goanalysis
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"go/token"
+	"go/types"
+	"log"
+	"os"
+	"reflect"
+	"sort"
+
+	"github.com/keploy/keploy-review-agent/pkg/models"
+	"golang.org/x/tools/go/analysis"
+	"golang.org/x/tools/go/analysis/passes/appends"
+	"golang.org/x/tools/go/analysis/passes/assign"
+	"golang.org/x/tools/go/analysis/passes/atomic"
+	"golang.org/x/tools/go/analysis/passes/bools"
+	"golang.org/x/tools/go/analysis/passes/composite"
+	"golang.org/x/tools/go/analysis/passes/copylock"
+	"golang.org/x/tools/go/analysis/passes/defers"
+	"golang.org/x/tools/go/analysis/passes/directive"
+	"golang.org/x/tools/go/analysis/passes/errorsas"
+	"golang.org/x/tools/go/analysis/passes/httpresponse"
+	"golang.org/x/tools/go/analysis/passes/ifaceassert"
+	"golang.org/x/tools/go/analysis/passes/loopclosure"
+	"golang.org/x/tools/go/analysis/passes/lostcancel"
+	"golang.org/x/tools/go/analysis/passes/nilfunc"
+	"golang.org/x/tools/go/analysis/passes/nilness"
+	"golang.org/x/tools/go/analysis/passes/printf"
+	"golang.org/x/tools/go/analysis/passes/shadow"
+	"golang.org/x/tools/go/analysis/passes/shift"
+	"golang.org/x/tools/go/analysis/passes/sigchanyzer"
+	"golang.org/x/tools/go/analysis/passes/slog"
+	"golang.org/x/tools/go/analysis/passes/stdmethods"
+	"golang.org/x/tools/go/analysis/passes/stringintconv"
+	"golang.org/x/tools/go/analysis/passes/structtag"
+	"golang.org/x/tools/go/analysis/passes/testinggoroutine"
+	"golang.org/x/tools/go/analysis/passes/tests"
+	"golang.org/x/tools/go/analysis/passes/timeformat"
+	"golang.org/x/tools/go/analysis/passes/unmarshal"
+	"golang.org/x/tools/go/analysis/passes/unreachable"
+	"golang.org/x/tools/go/analysis/passes/unsafeptr"
+	"golang.org/x/tools/go/analysis/passes/unusedresult"
+	"golang.org/x/tools/go/packages"
+)
+
+// vetSuite are the passes of go vet that apply to source code, without
+// those checking assembly, cgo or build tags.
+var vetSuite = []*analysis.Analyzer{
+	appends.Analyzer,
+	assign.Analyzer,
+	atomic.Analyzer,
+	bools.Analyzer,
+	composite.Analyzer,
+	copylock.Analyzer,
+	defers.Analyzer,
+	directive.Analyzer,
+	errorsas.Analyzer,
+	httpresponse.Analyzer,
+	ifaceassert.Analyzer,
+	loopclosure.Analyzer,
+	lostcancel.Analyzer,
+	nilfunc.Analyzer,
+	printf.Analyzer,
+	shift.Analyzer,
+	sigchanyzer.Analyzer,
+	slog.Analyzer,
+	stdmethods.Analyzer,
+	stringintconv.Analyzer,
+	structtag.Analyzer,
+	testinggoroutine.Analyzer,
+	tests.Analyzer,
+	timeformat.Analyzer,
+	unmarshal.Analyzer,
+	unreachable.Analyzer,
+	unsafeptr.Analyzer,
+	unusedresult.Analyzer,
+}
+
+// Analyzers returns the curated passes: the go vet suite, nilness, shadow
+// and the passes of this repository, without the disabled ones.
+func Analyzers(disabled []string) []*analysis.Analyzer {
+	skip := make(map[string]bool, len(disabled))
+	for _, name := range disabled {
+		skip[name] = true
+	}
+
+	all := append(append([]*analysis.Analyzer{}, vetSuite...),
+		nilness.Analyzer, shadow.Analyzer, ErrWrapAnalyzer, ContextAnalyzer)
+	var analyzers []*analysis.Analyzer
+	for _, a := range all {
+		if !skip[a.Name] {
+			analyzers = append(analyzers, a)
+		}
+	}
+	return analyzers
+}
+
+// loadMode loads the syntax and types of the matched packages and their
+// dependencies. Dependencies are type-checked from source rather than from
+// export data, whose format follows the Go toolchain on the host and can be
+// newer than the one go/packages reads.
+const loadMode = packages.NeedName | packages.NeedFiles | packages.NeedCompiledGoFiles | packages.NeedImports |
+	packages.NeedDeps | packages.NeedTypes | packages.NeedTypesSizes | packages.NeedSyntax | packages.NeedTypesInfo
+
+// Run loads the packages matching patterns in dir, with their tests, and
+// runs the analyzers on them. A nil env uses the environment of the process.
+// Issues carry absolute file paths.
+func Run(ctx context.Context, dir string, env []string, patterns []string, analyzers []*analysis.Analyzer) ([]*models.Issue, error) {
+	cfg := &packages.Config{
+		Context: ctx,
+		Mode:    loadMode,
+		Dir:     dir,
+		Env:     env,
+		Tests:   true,
+		Fset:    token.NewFileSet(),
+	}
+	pkgs, err := packages.Load(cfg, patterns...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load packages: %w", err)
+	}
+
+	d := &driver{
+		fset:         cfg.Fset,
+		objectFacts:  make(map[objectFactKey]analysis.Fact),
+		packageFacts: make(map[packageFactKey]analysis.Fact),
+		reported:     make(map[string]bool),
+	}
+	// Imported packages come first, so that their facts are known.
+	var ordered []*packages.Package
+	packages.Visit(pkgs, nil, func(pkg *packages.Package) {
+		ordered = append(ordered, pkg)
+	})
+	roots := make(map[*packages.Package]bool, len(pkgs))
+	for _, pkg := range pkgs {
+		roots[pkg] = true
+	}
+
+	broken := 0
+	for _, pkg := range ordered {
+		if !roots[pkg] || pkg.Types == nil || pkg.TypesInfo == nil || len(pkg.Syntax) == 0 {
+			continue
+		}
+		if len(pkg.Errors) > 0 {
+			broken++
+		}
+		memo := make(map[*analysis.Analyzer]*result)
+		for _, a := range analyzers {
+			if _, err := d.exec(a, pkg, memo); err != nil && !errors.Is(err, errBroken) {
+				log.Printf("Warning: %s failed on %s: %v", a.Name, pkg.ID, err)
+			}
+		}
+	}
+	if broken > 0 {
+		log.Printf("%d Go packages have load errors; only analyzers tolerating them ran", broken)
+	}
+
+	sort.SliceStable(d.issues, func(i, j int) bool {
+		a, b := d.issues[i], d.issues[j]
+		if a.Path != b.Path {
+			return a.Path < b.Path
+		}
+		return a.Line < b.Line
+	})
+	return d.issues, nil
+}
+
+var errBroken = errors.New("package has errors")
+
+type result struct {
+	value interface{}
+	err   error
+}
+
+type objectFactKey struct {
+	analyzer *analysis.Analyzer
+	obj      types.Object
+	typ      reflect.Type
+}
+
+type packageFactKey struct {
+	analyzer *analysis.Analyzer
+	pkg      *types.Package
+	typ      reflect.Type
+}
+
+// driver runs analyzers on loaded packages. Facts are shared between the
+// analyzed packages; dependencies outside them contribute none.
+type driver struct {
+	fset         *token.FileSet
+	objectFacts  map[objectFactKey]analysis.Fact
+	packageFacts map[packageFactKey]analysis.Fact
+	reported     map[string]bool // test variants of a package repeat its diagnostics
+	issues       []*models.Issue
+}
+
+// exec runs an analyzer and its requirements on a package once.
+func (d *driver) exec(a *analysis.Analyzer, pkg *packages.Package, memo map[*analysis.Analyzer]*result) (interface{}, error) {
+	if r, ok := memo[a]; ok {
+		return r.value, r.err
+	}
+	r := &result{}
+	memo[a] = r
+
+	resultOf := make(map[*analysis.Analyzer]interface{}, len(a.Requires))
+	for _, req := range a.Requires {
+		value, err := d.exec(req, pkg, memo)
+		if err != nil {
+			r.err = err
+			return nil, err
+		}
+		resultOf[req] = value
+	}
+	if len(pkg.Errors) > 0 && !a.RunDespiteErrors {
+		r.err = errBroken
+		return nil, r.err
+	}
+
+	var typeErrors []types.Error
+	if a.RunDespiteErrors {
+		typeErrors = pkg.TypeErrors
+	}
+	pass := &analysis.Pass{
+		Analyzer:   a,
+		Fset:       d.fset,
+		Files:      pkg.Syntax,
+		OtherFiles: pkg.OtherFiles,
+		Pkg:        pkg.Types,
+		TypesInfo:  pkg.TypesInfo,
+		TypesSizes: pkg.TypesSizes,
+		TypeErrors: typeErrors,
+		ResultOf:   resultOf,
+		ReadFile:   os.ReadFile,
+		Report: func(diag analysis.Diagnostic) {
+			d.report(a, diag)
+		},
+		ImportObjectFact: func(obj types.Object, fact analysis.Fact) bool {
+			return importFact(d.objectFacts[objectFactKey{a, obj, reflect.TypeOf(fact)}], fact)
+		},
+		ExportObjectFact: func(obj types.Object, fact analysis.Fact) {
+			d.objectFacts[objectFactKey{a, obj, reflect.TypeOf(fact)}] = fact
+		},
+		ImportPackageFact: func(p *types.Package, fact analysis.Fact) bool {
+			return importFact(d.packageFacts[packageFactKey{a, p, reflect.TypeOf(fact)}], fact)
+		},
+		ExportPackageFact: func(fact analysis.Fact) {
+			d.packageFacts[packageFactKey{a, pkg.Types, reflect.TypeOf(fact)}] = fact
+		},
+		AllObjectFacts: func() []analysis.ObjectFact {
+			var facts []analysis.ObjectFact
+			for key, fact := range d.objectFacts {
+				if key.analyzer == a {
+					facts = append(facts, analysis.ObjectFact{Object: key.obj, Fact: fact})
+				}
+			}
+			return facts
+		},
+		AllPackageFacts: func() []analysis.PackageFact {
+			var facts []analysis.PackageFact
+			for key, fact := range d.packageFacts {
+				if key.analyzer == a {
+					facts = append(facts, analysis.PackageFact{Package: key.pkg, Fact: fact})
+				}
+			}
+			return facts
+		},
+	}
+
+	func() {
+		defer func() {
+			if p := recover(); p != nil {
+				r.err = fmt.Errorf("panic: %v", p)
+			}
+		}()
+		r.value, r.err = a.Run(pass)
+	}()
+	return r.value, r.err
+}
+
+// importFact copies a stored fact into the fact passed by an analyzer.
+func importFact(stored, fact analysis.Fact) bool {
+	if stored == nil {
+		return false
+	}
+	reflect.ValueOf(fact).Elem().Set(reflect.ValueOf(stored).Elem())
+	return true
+}
+
+func (d *driver) report(a *analysis.Analyzer, diag analysis.Diagnostic) {
+	pos := d.fset.Position(diag.Pos)
+	if !pos.IsValid() {
+		return
+	}
+	end := pos
+	if diag.End.IsValid() {
+		end = d.fset.Position(diag.End)
+	}
+	key := fmt.Sprintf("%s:%s:%s", a.Name, pos, diag.Message)
+	if d.reported[key] {
+		return
+	}
+	d.reported[key] = true
+
+	url := diag.URL
+	if url == "" {
+		url = a.URL
+	}
+	issue := &models.Issue{
+		Path:        pos.Filename,
+		Line:        pos.Line,
+		Column:      pos.Column,
+		EndLine:     end.Line,
+		EndColumn:   end.Column,
+		Severity:    models.SeverityWarning,
+		RuleID:      a.Name,
+		Category:    "lint",
+		Title:       fmt.Sprintf("Go Analysis Issue: %s", a.Name),
+		Description: diag.Message,
+		DocURL:      url,
+		Source:      "go/analysis",
+	}
+	if a == nilness.Analyzer {
+		issue.Severity = models.SeverityError
+		issue.Category = "bug"
+	}
+	for _, sf := range diag.SuggestedFixes {
+		if fix, ok := d.fix(pos.Filename, sf); ok {
+			issue.Fixes = append(issue.Fixes, fix)
+		}
+	}
+	d.issues = append(d.issues, issue)
+}
+
+// fix converts a suggested fix, which may only edit the file of its issue.
+func (d *driver) fix(filename string, sf analysis.SuggestedFix) (models.Fix, bool) {
+	fix := models.Fix{Description: sf.Message}
+	for _, edit := range sf.TextEdits {
+		start := d.fset.Position(edit.Pos)
+		end := start
+		if edit.End.IsValid() {
+			end = d.fset.Position(edit.End)
+		}
+		if start.Filename != filename || end.Filename != filename {
+			return models.Fix{}, false
+		}
+		fix.Edits = append(fix.Edits, models.TextEdit{
+			StartLine:   start.Line,
+			StartColumn: start.Column,
+			EndLine:     end.Line,
+			EndColumn:   end.Column,
+			NewText:     string(edit.NewText),
+		})
+	}
+	return fix, len(fix.Edits) > 0
+}
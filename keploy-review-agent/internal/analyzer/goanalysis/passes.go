@@ -0,0 +1,174 @@
+package goanalysis
+
+import (
+	"go/ast"
+	"go/token"
+	"go/types"
+	"strings"
+
+	"golang.org/x/tools/go/analysis"
+	"golang.org/x/tools/go/analysis/passes/inspect"
+	"golang.org/x/tools/go/ast/inspector"
+	"golang.org/x/tools/go/types/typeutil"
+)
+
+// ErrWrapAnalyzer reports errors formatted into fmt.Errorf with %v or %s,
+// which loses the error chain that errors.Is and errors.As inspect.
+var ErrWrapAnalyzer = &analysis.Analyzer{
+	Name:     "errwrap",
+	Doc:      "report errors passed to fmt.Errorf without %w",
+	Requires: []*analysis.Analyzer{inspect.Analyzer},
+	Run:      runErrWrap,
+}
+
+// ContextAnalyzer reports context.Background and context.TODO called where
+// a context parameter is in scope, which detaches the call from the
+// caller's cancellation and deadline.
+var ContextAnalyzer = &analysis.Analyzer{
+	Name:     "ctxbackground",
+	Doc:      "report new root contexts created where a context parameter is available",
+	Requires: []*analysis.Analyzer{inspect.Analyzer},
+	Run:      runContext,
+}
+
+var errorType = types.Universe.Lookup("error").Type().Underlying().(*types.Interface)
+
+func runErrWrap(pass *analysis.Pass) (interface{}, error) {
+	in := pass.ResultOf[inspect.Analyzer].(*inspector.Inspector)
+	in.Preorder([]ast.Node{(*ast.CallExpr)(nil)}, func(n ast.Node) {
+		call := n.(*ast.CallExpr)
+		fn, ok := typeutil.Callee(pass.TypesInfo, call).(*types.Func)
+		if !ok || fn.FullName() != "fmt.Errorf" || len(call.Args) < 2 {
+			return
+		}
+		lit, ok := call.Args[0].(*ast.BasicLit)
+		if !ok || lit.Kind != token.STRING {
+			return
+		}
+
+		for _, verb := range formatVerbs(lit.Value) {
+			if verb.arg+1 >= len(call.Args) {
+				return
+			}
+			if verb.text != "%v" && verb.text != "%s" {
+				continue
+			}
+			arg := call.Args[verb.arg+1]
+			t := pass.TypesInfo.TypeOf(arg)
+			if t == nil || !types.Implements(t, errorType) {
+				continue
+			}
+			pos := lit.Pos() + token.Pos(verb.offset)
+			end := pos + token.Pos(len(verb.text))
+			pass.Report(analysis.Diagnostic{
+				Pos:     pos,
+				End:     end,
+				Message: "error formatted with " + verb.text + " loses its chain; wrap it with %w",
+				SuggestedFixes: []analysis.SuggestedFix{{
+					Message:   "Wrap the error with %w",
+					TextEdits: []analysis.TextEdit{{Pos: pos, End: end, NewText: []byte("%w")}},
+				}},
+			})
+		}
+	})
+	return nil, nil
+}
+
+type formatVerb struct {
+	text   string // the whole directive, such as "%v" or "%-8s"
+	offset int    // byte offset in the literal
+	arg    int    // index of the operand among the arguments after the format
+}
+
+// formatVerbs returns the verbs of a format string literal in source form.
+// Formats with explicit argument indexes or * widths are not supported and
+// yield no verbs, since their operands cannot be matched without
+// evaluating them.
+func formatVerbs(literal string) []formatVerb {
+	var verbs []formatVerb
+	arg := 0
+	for i := 0; i < len(literal); i++ {
+		if literal[i] != '%' {
+			continue
+		}
+		j := i + 1
+		for j < len(literal) && strings.IndexByte("+-# 0123456789.", literal[j]) >= 0 {
+			j++
+		}
+		if j >= len(literal) {
+			break
+		}
+		switch c := literal[j]; {
+		case c == '%':
+		case c == '[' || c == '*':
+			return nil
+		default:
+			verbs = append(verbs, formatVerb{text: literal[i : j+1], offset: i, arg: arg})
+			arg++
+		}
+		i = j
+	}
+	return verbs
+}
+
+func runContext(pass *analysis.Pass) (interface{}, error) {
+	in := pass.ResultOf[inspect.Analyzer].(*inspector.Inspector)
+	in.WithStack([]ast.Node{(*ast.CallExpr)(nil)}, func(n ast.Node, push bool, stack []ast.Node) bool {
+		if !push {
+			return true
+		}
+		call := n.(*ast.CallExpr)
+		fn, ok := typeutil.Callee(pass.TypesInfo, call).(*types.Func)
+		if !ok || (fn.FullName() != "context.Background" && fn.FullName() != "context.TODO") {
+			return true
+		}
+
+		for i := len(stack) - 1; i >= 0; i-- {
+			var ftype *ast.FuncType
+			switch f := stack[i].(type) {
+			case *ast.FuncDecl:
+				ftype = f.Type
+			case *ast.FuncLit:
+				ftype = f.Type
+			default:
+				continue
+			}
+			name := contextParam(pass.TypesInfo, ftype)
+			if name == "" {
+				continue
+			}
+			pass.Report(analysis.Diagnostic{
+				Pos:     call.Pos(),
+				End:     call.End(),
+				Message: fn.FullName() + " ignores the cancellation of " + name + "; pass " + name + " instead",
+				SuggestedFixes: []analysis.SuggestedFix{{
+					Message:   "Use " + name,
+					TextEdits: []analysis.TextEdit{{Pos: call.Pos(), End: call.End(), NewText: []byte(name)}},
+				}},
+			})
+			break
+		}
+		return true
+	})
+	return nil, nil
+}
+
+// contextParam returns the name of the first context.Context parameter of a
+// function, or "" when it has none usable.
+func contextParam(info *types.Info, ftype *ast.FuncType) string {
+	if ftype.Params == nil {
+		return ""
+	}
+	for _, field := range ftype.Params.List {
+		t := info.TypeOf(field.Type)
+		if t == nil || t.String() != "context.Context" {
+			continue
+		}
+		for _, name := range field.Names {
+			if name.Name != "_" {
+				return name.Name
+			}
+		}
+	}
+	return ""
+}
@@ -0,0 +1,103 @@
+package analyzer
+
+import (
+	"context"
+	"log"
+	"strings"
+
+	"github.com/keploy/keploy-review-agent/internal/formatter"
+	"github.com/keploy/keploy-review-agent/pkg/github"
+	"github.com/keploy/keploy-review-agent/pkg/models"
+)
+
+// reviewHistory is what earlier reviews and developers recorded in the
+// comments of a pull request.
+type reviewHistory struct {
+	posted       map[string]bool // fingerprints of issues already reported
+	suppressed   map[string]bool // fingerprints of issues developers dismissed
+	ignoredRules map[string]bool // rules ignored with /ignore
+	reacted      []reactedComment
+}
+
+// reactedComment is a comment of the agent reporting issues that has
+// reactions, whose authors are only known once they are listed.
+type reactedComment struct {
+	id           int64
+	review       bool // an inline review comment
+	fingerprints []string
+}
+
+// fetchHistory reads the hidden markers of the comments the agent posted on
+// the pull request; markers copied into comments by anyone else are ignored.
+// The returned history is usable, though possibly incomplete, on error.
+func (o *Orchestrator) fetchHistory(ctx context.Context, job *Job) (*reviewHistory, error) {
+	history := &reviewHistory{
+		posted:       make(map[string]bool),
+		suppressed:   make(map[string]bool),
+		ignoredRules: make(map[string]bool),
+	}
+	if job.Provider != "github" {
+		return history, nil
+	}
+
+	reviewComments, err := o.githubClient.ListReviewComments(ctx, job.RepoOwner, job.RepoName, job.PRNumber)
+	if err != nil {
+		return history, err
+	}
+	issueComments, err := o.githubClient.ListIssueComments(ctx, job.RepoOwner, job.RepoName, job.PRNumber)
+	if err != nil {
+		return history, err
+	}
+
+	for i, comment := range append(reviewComments, issueComments...) {
+		if !o.postedByAgent(comment) {
+			continue
+		}
+		fingerprints := formatter.ExtractFingerprints(comment.Body)
+		for _, fp := range fingerprints {
+			history.posted[fp] = true
+		}
+		if len(fingerprints) > 0 && comment.Reactions.ThumbsUp+comment.Reactions.ThumbsDown > 0 {
+			history.reacted = append(history.reacted, reactedComment{
+				id:           comment.ID,
+				review:       i < len(reviewComments),
+				fingerprints: fingerprints,
+			})
+		}
+		for _, fp := range formatter.ExtractSuppressions(comment.Body) {
+			history.suppressed[fp] = true
+		}
+		for _, rule := range formatter.ExtractIgnoredRules(comment.Body) {
+			history.ignoredRules[rule] = true
+		}
+	}
+	return history, nil
+}
+
+// postedByAgent reports whether a comment was posted by the agent, under its
+// configured login or through its configured GitHub App. Comments of other
+// bots are not the agent's, even when they carry its markers.
+func (o *Orchestrator) postedByAgent(comment *github.Comment) bool {
+	if o.cfg.GitHubAppID != 0 && comment.App != nil && comment.App.ID == o.cfg.GitHubAppID {
+		return true
+	}
+	return strings.EqualFold(strings.TrimSuffix(comment.User.Login, "[bot]"), o.cfg.GitHubBotLogin)
+}
+
+// dropDismissed removes the issues developers dismissed on the pull request,
+// either one by one or by ignoring their rule.
+func (h *reviewHistory) dropDismissed(issues []*models.Issue) []*models.Issue {
+	if len(h.suppressed) == 0 && len(h.ignoredRules) == 0 {
+		return issues
+	}
+	var kept []*models.Issue
+	for _, issue := range issues {
+		if !h.suppressed[issue.Fingerprint] && !h.ignoredRules[issue.RuleID] {
+			kept = append(kept, issue)
+		}
+	}
+	if dropped := len(issues) - len(kept); dropped > 0 {
+		log.Printf("Dropped %d issues dismissed on the pull request", dropped)
+	}
+	return kept
+}
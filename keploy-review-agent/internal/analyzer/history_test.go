@@ -0,0 +1,37 @@
+package analyzer
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/keploy/keploy-review-agent/internal/config"
+	"github.com/keploy/keploy-review-agent/pkg/github"
+)
+
+func TestPostedByAgent(t *testing.T) {
+	tests := []struct {
+		name    string
+		appID   int64
+		comment string
+		want    bool
+	}{
+		{"configured login", 0, `{"user": {"login": "keploy-review", "type": "User"}}`, true},
+		{"configured login as an app bot", 0, `{"user": {"login": "Keploy-Review[bot]", "type": "Bot"}}`, true},
+		{"configured app", 42, `{"user": {"login": "other-name[bot]", "type": "Bot"}, "performed_via_github_app": {"id": 42}}`, true},
+		{"other app", 42, `{"user": {"login": "dependabot[bot]", "type": "Bot"}, "performed_via_github_app": {"id": 7}}`, false},
+		{"other bot", 0, `{"user": {"login": "github-actions[bot]", "type": "Bot"}}`, false},
+		{"other user", 42, `{"user": {"login": "octocat", "type": "User"}}`, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var comment github.Comment
+			if err := json.Unmarshal([]byte(tt.comment), &comment); err != nil {
+				t.Fatal(err)
+			}
+			o := &Orchestrator{cfg: &config.Config{GitHubBotLogin: "keploy-review", GitHubAppID: tt.appID}}
+			if got := o.postedByAgent(&comment); got != tt.want {
+				t.Errorf("postedByAgent() = %t, want %t", got, tt.want)
+			}
+		})
+	}
+}
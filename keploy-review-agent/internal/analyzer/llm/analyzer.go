@@ -0,0 +1,313 @@
+package llm
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/json"
+	"fmt"
+	"log"
+	"path/filepath"
+	"strings"
+	"sync"
+
+	"github.com/keploy/keploy-review-agent/pkg/models"
+)
+
+// promptVersion changes whenever the prompt or the response handling change
+// in a way that invalidates cached results.
+const promptVersion = "4"
+
+// Analyzer reviews code with a language model through an LLMProvider.
+type Analyzer struct {
+	provider LLMProvider
+	limiter  *Limiter
+	config   *AIConfig
+}
+
+type AIConfig struct {
+	MaxTokens       int
+	Temperature     float64
+	MinSeverity     models.Severity
+	DiffContext     int    // unchanged lines sent around each changed hunk
+	ChunkTokens     int    // maximum code tokens per prompt; 0 sends files whole
+	PRTokenBudget   int    // maximum tokens per pull request; 0 is unlimited
+	Concurrency     int    // files analyzed in parallel
+	PromptAdditions string // repository-specific instructions appended to the prompt
+}
+
+// NewAnalyzer returns an analyzer sending its requests to provider through
+// limiter, which may be nil when requests are not rate limited.
+func NewAnalyzer(provider LLMProvider, limiter *Limiter, cfg *AIConfig) *Analyzer {
+	return &Analyzer{
+		provider: provider,
+		limiter:  limiter,
+		config:   cfg,
+	}
+}
+
+// WithConfig returns an analyzer sharing the provider and limiter but using
+// cfg, so a single review can adjust thresholds and prompts.
+func (g *Analyzer) WithConfig(cfg *AIConfig) *Analyzer {
+	return &Analyzer{
+		provider: g.provider,
+		limiter:  g.limiter,
+		config:   cfg,
+	}
+}
+
+// CacheVersion identifies the prompt and settings that determine the results
+// of the analyzer, for caching.
+func (g *Analyzer) CacheVersion() string {
+	c := g.config
+	return fmt.Sprintf("%s:%d:%g:%s:%d:%d:%x", promptVersion, c.MaxTokens, c.Temperature, c.MinSeverity,
+		c.DiffContext, c.ChunkTokens, sha256.Sum256([]byte(c.PromptAdditions)))
+}
+
+// Model identifies the provider and model answering the prompts.
+func (g *Analyzer) Model() string {
+	return g.provider.Name()
+}
+
+// PartialError reports files that could not be analyzed, either because a
+// request failed or because they did not fit in the token budget. The
+// results of the other files are still returned.
+type PartialError struct {
+	Paths []string
+}
+
+func (e *PartialError) Error() string {
+	return fmt.Sprintf("analysis incomplete for %d files: %s", len(e.Paths), strings.Join(e.Paths, ", "))
+}
+
+func (e *PartialError) FailedPaths() []string {
+	return e.Paths
+}
+
+// tokenUsage counts the tokens used by concurrent requests.
+type tokenUsage struct {
+	mu     sync.Mutex
+	input  int
+	output int
+}
+
+func (u *tokenUsage) add(resp *Response) {
+	u.mu.Lock()
+	defer u.mu.Unlock()
+	u.input += resp.InputTokens
+	u.output += resp.OutputTokens
+}
+
+func (g *Analyzer) AnalyzeCode(ctx context.Context, files []*models.File) ([]*models.Issue, error) {
+
+	var allIssues []*models.Issue
+
+	var reviewed []*models.File
+	for _, file := range files {
+		if shouldSkipFile(file.Path) {
+			log.Printf("Skipping file: %s", file.Path)
+			continue
+		}
+		reviewed = append(reviewed, file)
+	}
+
+	work, failed := g.plan(ctx, reviewed)
+	results := make([][]*models.Issue, len(work))
+	failedFiles := make([]bool, len(work))
+	usage := &tokenUsage{}
+
+	workers := g.config.Concurrency
+	if workers < 1 {
+		workers = 1
+	}
+	next := make(chan int)
+	var wg sync.WaitGroup
+	for w := 0; w < workers; w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range next {
+				results[i], failedFiles[i] = g.analyzeFile(ctx, work[i], usage)
+			}
+		}()
+	}
+	for i := range work {
+		next <- i
+	}
+	close(next)
+	wg.Wait()
+
+	// Results are collected in plan order so the output does not depend on
+	// which worker finished first.
+	for i, issues := range results {
+		allIssues = append(allIssues, models.FilterBySeverity(issues, g.config.MinSeverity)...)
+		if failedFiles[i] {
+			failed = append(failed, work[i].view.file.Path)
+		}
+	}
+
+	log.Printf("AI analysis used %d input and %d output tokens", usage.input, usage.output)
+	log.Printf("AnalyzeCode: Completed analysis with %d total issues", len(allIssues))
+	if len(failed) > 0 {
+		return allIssues, &PartialError{Paths: failed}
+	}
+	return allIssues, nil
+}
+
+func shouldSkipFile(path string) bool {
+	ext := filepath.Ext(path)
+	skip := !(ext == ".go" || ext == ".js" || ext == ".ts" || ext == ".py")
+
+	return skip
+}
+
+// analyzeFile reviews every chunk of a file and adds the tokens used to
+// usage. Chunks are rendered with their real line numbers, so their issues
+// need no offset. A failed chunk does not stop the others, but the file is
+// reported as failed.
+func (g *Analyzer) analyzeFile(ctx context.Context, work *fileWork, usage *tokenUsage) ([]*models.Issue, bool) {
+	file := work.view.file
+	log.Printf("Analyzing file: %s in %d chunks", file.Path, len(work.chunks))
+
+	var issues []*models.Issue
+	failed := false
+	for i, chunk := range work.chunks {
+		code := work.view.render(chunk)
+		if len(work.chunks) > 1 {
+			code = fmt.Sprintf("(part %d of %d of %s)\n%s", i+1, len(work.chunks), file.Path, code)
+		}
+		prompt := buildPrompt(code, work.view.isDiff(), g.config.PromptAdditions)
+
+		chunkIssues, err := g.review(ctx, prompt, file.Path, usage)
+		if err != nil {
+			log.Printf("AI analysis failed for %s (part %d of %d): %v", file.Path, i+1, len(work.chunks), err)
+			failed = true
+			continue
+		}
+		issues = append(issues, chunkIssues...)
+	}
+
+	if work.view.isDiff() {
+		issues = keepInHunks(issues, work.view.hunks)
+	}
+	return issues, failed
+}
+
+// review sends a prompt and validates the answer. When the answer fails
+// validation the model is asked once to repair it; items that are still
+// invalid after that are rejected individually.
+func (g *Analyzer) review(ctx context.Context, prompt, filePath string, usage *tokenUsage) ([]*models.Issue, error) {
+	messages := []Message{{Role: RoleUser, Content: prompt}}
+	response, err := g.complete(ctx, messages, issuesSchema, usage)
+	if err != nil {
+		return nil, err
+	}
+
+	issues, problems, err := parseAIResponse(response.Text, filePath)
+	if err == nil && len(problems) == 0 {
+		return issues, nil
+	}
+	if err != nil {
+		problems = []string{err.Error()}
+	}
+	log.Printf("AI response for %s failed validation, asking for a repair: %s", filePath, strings.Join(problems, "; "))
+
+	messages = append(messages,
+		Message{Role: RoleAssistant, Content: response.Text},
+		Message{Role: RoleUser, Content: repairPrompt(problems)},
+	)
+	repaired, repairErr := g.complete(ctx, messages, issuesSchema, usage)
+	if repairErr == nil {
+		var repairedIssues []*models.Issue
+		var repairedProblems []string
+		repairedIssues, repairedProblems, repairErr = parseAIResponse(repaired.Text, filePath)
+		if repairErr == nil {
+			logRejected(filePath, repairedProblems)
+			return repairedIssues, nil
+		}
+	}
+
+	if err != nil {
+		return nil, fmt.Errorf("invalid response after repair: %w", repairErr)
+	}
+	// The repair failed, so keep the valid items of the first answer.
+	logRejected(filePath, problems)
+	return issues, nil
+}
+
+func logRejected(filePath string, problems []string) {
+	for _, problem := range problems {
+		log.Printf("Rejected AI issue for %s: %s", filePath, problem)
+	}
+}
+
+// complete generates a response matching schema through the rate limiter,
+// retrying rate limited and failed requests, and adds the tokens used to usage.
+func (g *Analyzer) complete(ctx context.Context, messages []Message, schema json.RawMessage, usage *tokenUsage) (*Response, error) {
+	estimated := estimateMessageTokens(messages) + g.config.MaxTokens
+
+	for attempt := 0; ; attempt++ {
+		if err := g.limiter.Wait(ctx, estimated); err != nil {
+			return nil, err
+		}
+
+		response, err := g.provider.Generate(ctx, &Request{
+			Messages:    messages,
+			Schema:      schema,
+			MaxTokens:   g.config.MaxTokens,
+			Temperature: g.config.Temperature,
+		})
+		if err == nil {
+			g.limiter.Adjust(estimated, response.InputTokens+response.OutputTokens)
+			usage.add(response)
+			return response, nil
+		}
+
+		delay, retry := retryDelay(err, attempt)
+		if !retry || attempt+1 >= maxRetries {
+			return nil, err
+		}
+		log.Printf("Request to %s failed, retrying in %s: %v", g.provider.Name(), delay, err)
+		if err := sleep(ctx, delay); err != nil {
+			return nil, err
+		}
+	}
+}
+
+func buildPrompt(code string, isDiff bool, additions string) string {
+	intro := `Analyze this code for security, performance, and maintainability issues.
+Each line is prefixed with its line number.`
+	if isDiff {
+		intro = `Analyze the changes in this diff for security, performance, and maintainability issues.
+Each line is prefixed with its line number in the new version of the file. Lines marked
+with + were added, lines marked with - were removed and have no number, and other lines
+are unchanged context. Only report issues in the added lines.`
+	}
+
+	prompt := fmt.Sprintf(`%s
+
+Code:
+%s
+
+Respond with only JSON in this format:
+{"issues": [{
+	"line": <number>,
+	"end_line": <number, last line of the issue>,
+	"category": "security|performance|maintainability|error_handling",
+	"description": "<concise issue description>",
+	"severity": "critical|high|medium|low",
+	"suggestion": "<specific improvement suggestion>",
+	"cwe": "<CWE identifier such as CWE-89, security issues only>",
+	"confidence": 0-1
+}]}
+
+Rules:
+1. Only report issues with confidence >= 0.7
+2. Use the line numbers shown next to the code for line and end_line
+3. Suggest concrete fixes
+4. Avoid trivial/style-only issues`, intro, code)
+
+	if additions != "" {
+		prompt += "\n\nAdditional instructions for this repository:\n" + additions
+	}
+	return prompt
+}
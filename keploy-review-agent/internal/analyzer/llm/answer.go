@@ -0,0 +1,43 @@
+package llm
+
+import (
+	"context"
+	"fmt"
+	"strings"
+)
+
+// Answer replies to a developer's question about the review of a pull
+// request. The findings under discussion, the code around them and the
+// earlier messages of the thread, oldest first, give the model the context
+// of the question.
+func (g *Analyzer) Answer(ctx context.Context, findings, code string, thread []string, question string) (string, error) {
+	var prompt strings.Builder
+	prompt.WriteString(`You are a code review assistant. A developer is asking about your review of a pull request.
+Answer their question briefly and concretely in Markdown. If a finding is wrong, say so
+plainly instead of defending it. Do not repeat the findings verbatim.
+
+Findings under discussion:
+`)
+	prompt.WriteString(findings)
+	if code != "" {
+		fmt.Fprintf(&prompt, "\n\nCode, each line prefixed with its line number:\n%s", code)
+	}
+	if len(thread) > 0 {
+		fmt.Fprintf(&prompt, "\n\nEarlier replies:\n%s", strings.Join(thread, "\n\n"))
+	}
+	fmt.Fprintf(&prompt, "\n\nDeveloper's question:\n%s", question)
+	if g.config.PromptAdditions != "" {
+		prompt.WriteString("\n\nAdditional instructions for this repository:\n" + g.config.PromptAdditions)
+	}
+
+	usage := &tokenUsage{}
+	response, err := g.complete(ctx, []Message{{Role: RoleUser, Content: prompt.String()}}, nil, usage)
+	if err != nil {
+		return "", err
+	}
+	answer := strings.TrimSpace(response.Text)
+	if answer == "" {
+		return "", fmt.Errorf("empty response")
+	}
+	return answer, nil
+}
@@ -0,0 +1,126 @@
+package llm
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/keploy/keploy-review-agent/pkg/models"
+)
+
+// File tiers, from the most to the least important to review.
+const (
+	tierSource = iota
+	tierTest
+	tierGenerated
+)
+
+var (
+	testFileRegex      = regexp.MustCompile(`(_test\.go|\.(test|spec)\.[jt]sx?|(^|/)test_[^/]*\.py|_test\.py)$|(^|/)(tests?|__tests__)/`)
+	generatedFileRegex = regexp.MustCompile(`(\.pb\.go|_gen\.go|\.gen\.[jt]s|\.min\.js|\.d\.ts)$|(^|/)(vendor|node_modules|generated|dist)/`)
+)
+
+// fileWork is the planned analysis of one file.
+type fileWork struct {
+	view   *codeView
+	chunks [][]lineRange
+	tokens int // estimated input and output tokens of every chunk
+}
+
+func fileTier(path string) int {
+	switch {
+	case generatedFileRegex.MatchString(path):
+		return tierGenerated
+	case testFileRegex.MatchString(path):
+		return tierTest
+	default:
+		return tierSource
+	}
+}
+
+// plan prepares the chunks of every file and keeps the files that fit in
+// the token budget of the pull request. Files are ranked by tier and then by
+// the number of changed lines; a file that does not fit in what is left of
+// the budget is dropped, and smaller files after it are still planned. The
+// paths of dropped files are returned too.
+func (g *Analyzer) plan(ctx context.Context, files []*models.File) ([]*fileWork, []string) {
+	window := 0
+	if p, ok := g.provider.(contextWindower); ok {
+		window = p.ContextWindow(ctx)
+	}
+
+	var work []*fileWork
+	for _, file := range files {
+		view := newCodeView(file, g.config.DiffContext)
+		prompt := promptTokens(view, g.config.PromptAdditions)
+		budget := g.config.ChunkTokens
+		if window > 0 {
+			budget = chunkBudget(budget, window-g.config.MaxTokens-prompt)
+		}
+		chunks := view.chunks(budget)
+		overhead := prompt + g.config.MaxTokens
+
+		tokens := 0
+		for _, chunk := range chunks {
+			for _, r := range chunk {
+				tokens += view.rangeTokens(r)
+			}
+			tokens += overhead
+		}
+		work = append(work, &fileWork{view: view, chunks: chunks, tokens: tokens})
+	}
+
+	if g.config.PRTokenBudget <= 0 {
+		return work, nil
+	}
+
+	sort.SliceStable(work, func(i, j int) bool {
+		ti, tj := fileTier(work[i].view.file.Path), fileTier(work[j].view.file.Path)
+		if ti != tj {
+			return ti < tj
+		}
+		return work[i].view.changedLines() > work[j].view.changedLines()
+	})
+
+	used := 0
+	var kept []*fileWork
+	var dropped []string
+	for _, w := range work {
+		if used+w.tokens > g.config.PRTokenBudget {
+			dropped = append(dropped, w.view.file.Path)
+			continue
+		}
+		used += w.tokens
+		kept = append(kept, w)
+	}
+	if len(dropped) > 0 {
+		log.Printf("AI token budget of %d reached, skipping %d files: %s",
+			g.config.PRTokenBudget, len(dropped), strings.Join(dropped, ", "))
+	}
+	return kept, dropped
+}
+
+// promptTokens estimates the tokens of a chunk prompt without its code: the
+// instructions, the part header of split files and the message framing.
+func promptTokens(view *codeView, additions string) int {
+	header := fmt.Sprintf("(part %d of %d of %s)\n", 999, 999, view.file.Path)
+	return EstimateTokens(buildPrompt("", view.isDiff(), additions)) + EstimateTokens(header) +
+		estimateMessageTokens([]Message{{Role: RoleUser}})
+}
+
+// chunkBudget returns the code tokens per chunk: the configured chunk size,
+// lowered to the tokens the context window of the model has left after the
+// prompt and the answer. A configured size of 0 sends files whole when they
+// fit.
+func chunkBudget(configured, available int) int {
+	if available < 1 {
+		available = 1
+	}
+	if configured <= 0 || available < configured {
+		return available
+	}
+	return configured
+}
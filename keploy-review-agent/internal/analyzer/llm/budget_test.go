@@ -0,0 +1,144 @@
+package llm
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/keploy/keploy-review-agent/pkg/models"
+)
+
+func TestPlan(t *testing.T) {
+	files := []*models.File{
+		{Path: "big.go", Content: strings.Repeat("fmt.Println(\"a long line of code\")\n", 200)},
+		{Path: "small.go", Content: "package small\n"},
+		{Path: "small_test.go", Content: "package small\n"},
+		{Path: "vendor/dep/dep.go", Content: "package dep\n"},
+	}
+
+	// The cost of each file, planned without a budget.
+	cost := make(map[string]int)
+	all, _ := (&Analyzer{config: &AIConfig{MaxTokens: 100}}).plan(context.Background(), files)
+	for _, w := range all {
+		cost[w.view.file.Path] = w.tokens
+	}
+
+	tests := []struct {
+		name        string
+		budget      int
+		wantKept    []string
+		wantDropped []string
+	}{
+		{
+			name:     "unlimited",
+			budget:   0,
+			wantKept: []string{"big.go", "small.go", "small_test.go", "vendor/dep/dep.go"},
+		},
+		{
+			name:     "everything fits",
+			budget:   cost["big.go"] + cost["small.go"] + cost["small_test.go"] + cost["vendor/dep/dep.go"],
+			wantKept: []string{"big.go", "small.go", "small_test.go", "vendor/dep/dep.go"},
+		},
+		{
+			name:        "an oversized file does not stop planning",
+			budget:      cost["small.go"] + cost["small_test.go"] + cost["vendor/dep/dep.go"],
+			wantKept:    []string{"small.go", "small_test.go", "vendor/dep/dep.go"},
+			wantDropped: []string{"big.go"},
+		},
+		{
+			name:        "lower tiers are dropped first",
+			budget:      cost["small.go"] + cost["small_test.go"],
+			wantKept:    []string{"small.go", "small_test.go"},
+			wantDropped: []string{"big.go", "vendor/dep/dep.go"},
+		},
+		{
+			name:        "nothing fits",
+			budget:      1,
+			wantDropped: []string{"big.go", "small.go", "small_test.go", "vendor/dep/dep.go"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			g := &Analyzer{config: &AIConfig{MaxTokens: 100, PRTokenBudget: tt.budget}}
+			work, dropped := g.plan(context.Background(), files)
+			var kept []string
+			for _, w := range work {
+				kept = append(kept, w.view.file.Path)
+			}
+			if !reflect.DeepEqual(kept, tt.wantKept) {
+				t.Errorf("kept %v, want %v", kept, tt.wantKept)
+			}
+			if !reflect.DeepEqual(dropped, tt.wantDropped) {
+				t.Errorf("dropped %v, want %v", dropped, tt.wantDropped)
+			}
+		})
+	}
+}
+
+func TestFileTier(t *testing.T) {
+	tests := []struct {
+		path string
+		want int
+	}{
+		{"main.go", tierSource},
+		{"src/app.ts", tierSource},
+		{"main_test.go", tierTest},
+		{"src/app.spec.ts", tierTest},
+		{"tests/helpers.py", tierTest},
+		{"api/api.pb.go", tierGenerated},
+		{"vendor/dep/dep.go", tierGenerated},
+		{"dist/app.min.js", tierGenerated},
+	}
+	for _, tt := range tests {
+		if got := fileTier(tt.path); got != tt.want {
+			t.Errorf("fileTier(%q) = %d, want %d", tt.path, got, tt.want)
+		}
+	}
+}
+
+// windowProvider is a provider reporting the context length of its model.
+type windowProvider struct {
+	LLMProvider
+	window int
+}
+
+func (p windowProvider) ContextWindow(ctx context.Context) int {
+	return p.window
+}
+
+func TestPlanContextWindow(t *testing.T) {
+	files := []*models.File{{Path: "big.go", Content: strings.Repeat("fmt.Println(\"a long line of code\")\n", 200)}}
+	const window = 1500
+
+	g := &Analyzer{provider: windowProvider{window: window}, config: &AIConfig{MaxTokens: 500, ChunkTokens: 6000}}
+	work, _ := g.plan(context.Background(), files)
+	if len(work) != 1 || len(work[0].chunks) < 2 {
+		t.Fatalf("plan() did not split the file to fit in the context window")
+	}
+	for i, chunk := range work[0].chunks {
+		code := fmt.Sprintf("(part %d of %d of %s)\n%s", i+1, len(work[0].chunks), "big.go", work[0].view.render(chunk))
+		messages := []Message{{Role: RoleUser, Content: buildPrompt(code, false, "")}}
+		if needed := estimateMessageTokens(messages) + g.config.MaxTokens; needed > window {
+			t.Errorf("chunk %d needs %d tokens, window is %d", i+1, needed, window)
+		}
+	}
+}
+
+func TestChunkBudget(t *testing.T) {
+	tests := []struct {
+		configured, available, want int
+	}{
+		{6000, 10000, 6000},
+		{6000, 2000, 2000},
+		{0, 2000, 2000},
+		{6000, -100, 1},
+	}
+	for _, tt := range tests {
+		if got := chunkBudget(tt.configured, tt.available); got != tt.want {
+			t.Errorf("chunkBudget(%d, %d) = %d, want %d", tt.configured, tt.available, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,142 @@
+package llm
+
+import (
+	"regexp"
+	"strings"
+)
+
+var (
+	// declarationRegex matches lines that start a top-level function, type or
+	// class in the languages the analyzer reviews.
+	declarationRegex = regexp.MustCompile(`^(func|type|var|const|class|def|async\s+def|function|async\s+function|export|interface|enum|abstract)\b`)
+	// leadingRegex matches comments and decorators that belong to the
+	// declaration below them.
+	leadingRegex = regexp.MustCompile(`^(//|#|/\*|\*|@)`)
+)
+
+// lineOverhead approximates the tokens of the line number prefix.
+const lineOverhead = 3
+
+// chunks splits the view into chunks of at most budget tokens. Ranges are cut
+// before top-level declarations where possible and small ranges are packed
+// together; a budget of 0 or less keeps the view in one chunk.
+func (v *codeView) chunks(budget int) [][]lineRange {
+	if budget <= 0 {
+		return [][]lineRange{v.ranges}
+	}
+
+	var pieces []lineRange
+	for _, r := range v.ranges {
+		pieces = append(pieces, v.splitRange(r, budget)...)
+	}
+
+	var chunks [][]lineRange
+	var current []lineRange
+	tokens := 0
+	for _, piece := range pieces {
+		t := v.rangeTokens(piece)
+		if len(current) > 0 && tokens+t > budget {
+			chunks = append(chunks, current)
+			current, tokens = nil, 0
+		}
+		current = append(current, piece)
+		tokens += t
+	}
+	if len(current) > 0 {
+		chunks = append(chunks, current)
+	}
+	return chunks
+}
+
+// splitRange splits r into ranges of at most budget tokens, cutting at
+// declarations. Declarations larger than the budget are cut between lines.
+func (v *codeView) splitRange(r lineRange, budget int) []lineRange {
+	if v.rangeTokens(r) <= budget {
+		return []lineRange{r}
+	}
+
+	starts := []int{r.start}
+	for n := r.start + 1; n <= r.end; n++ {
+		if !declarationRegex.MatchString(v.lines[n-1]) {
+			continue
+		}
+		if start := v.declarationStart(n, r.start); start > starts[len(starts)-1] {
+			starts = append(starts, start)
+		}
+	}
+
+	var result []lineRange
+	var current *lineRange
+	for i, start := range starts {
+		end := r.end
+		if i+1 < len(starts) {
+			end = starts[i+1] - 1
+		}
+		segment := lineRange{start, end}
+
+		if v.rangeTokens(segment) > budget {
+			if current != nil {
+				result = append(result, *current)
+				current = nil
+			}
+			result = append(result, v.splitLines(segment, budget)...)
+			continue
+		}
+		if current != nil && v.rangeTokens(lineRange{current.start, end}) > budget {
+			result = append(result, *current)
+			current = nil
+		}
+		if current == nil {
+			current = &lineRange{start, end}
+		} else {
+			current.end = end
+		}
+	}
+	if current != nil {
+		result = append(result, *current)
+	}
+	return result
+}
+
+// splitLines cuts r between lines into ranges of at most budget tokens.
+func (v *codeView) splitLines(r lineRange, budget int) []lineRange {
+	var result []lineRange
+	start, tokens := r.start, 0
+	for n := r.start; n <= r.end; n++ {
+		t := v.lineTokens(n)
+		if n > start && tokens+t > budget {
+			result = append(result, lineRange{start, n - 1})
+			start, tokens = n, 0
+		}
+		tokens += t
+	}
+	return append(result, lineRange{start, r.end})
+}
+
+// declarationStart returns the first line of the comments and decorators
+// directly above the declaration on line n, not going above min.
+func (v *codeView) declarationStart(n, min int) int {
+	for n > min && leadingRegex.MatchString(strings.TrimSpace(v.lines[n-2])) {
+		n--
+	}
+	return n
+}
+
+func (v *codeView) lineTokens(n int) int {
+	tokens := 0
+	for _, text := range v.removed[n] {
+		tokens += EstimateTokens(text) + lineOverhead
+	}
+	if n <= len(v.lines) {
+		tokens += EstimateTokens(v.lines[n-1]) + lineOverhead
+	}
+	return tokens
+}
+
+func (v *codeView) rangeTokens(r lineRange) int {
+	tokens := 0
+	for n := r.start; n <= r.end; n++ {
+		tokens += v.lineTokens(n)
+	}
+	return tokens
+}
@@ -0,0 +1,164 @@
+package llm
+
+import (
+	"fmt"
+	"log"
+	"strings"
+
+	"github.com/keploy/keploy-review-agent/internal/diff"
+	"github.com/keploy/keploy-review-agent/pkg/models"
+)
+
+// lineRange is an inclusive range of new-file line numbers.
+type lineRange struct {
+	start, end int
+}
+
+// codeView is the part of a file sent to the model, rendered with new-file
+// line numbers. When the file has a patch, only the changed hunks widened by
+// the configured context are shown and answers are checked against the
+// hunks; otherwise the whole file is shown.
+type codeView struct {
+	file    *models.File
+	lines   []string
+	hunks   []diff.Hunk
+	added   map[int]bool
+	removed map[int][]string // removed lines, keyed by the new-file line that follows them
+	ranges  []lineRange
+}
+
+func newCodeView(file *models.File, contextLines int) *codeView {
+	v := &codeView{
+		file:  file,
+		lines: strings.Split(file.Content, "\n"),
+	}
+	whole := []lineRange{{1, len(v.lines)}}
+
+	if file.Patch == "" {
+		v.ranges = whole
+		return v
+	}
+	hunks, err := diff.Parse(file.Patch)
+	if err != nil || len(hunks) == 0 {
+		if err != nil {
+			log.Printf("Warning: Failed to parse the diff of %s, sending the whole file: %v", file.Path, err)
+		}
+		v.ranges = whole
+		return v
+	}
+
+	v.hunks = hunks
+	v.added = diff.AddedLines(hunks)
+	v.removed = make(map[int][]string)
+	for _, h := range hunks {
+		next := h.NewStart
+		if h.NewLines == 0 {
+			next = h.NewStart + 1 // pure deletions start after NewStart
+		}
+		for _, l := range h.Lines {
+			switch l.Kind {
+			case diff.Removed:
+				v.removed[next] = append(v.removed[next], l.Text)
+			default:
+				next = l.NewLine + 1
+			}
+		}
+	}
+	v.ranges = windows(hunks, contextLines, len(v.lines))
+	return v
+}
+
+// isDiff reports whether the view shows hunks rather than the whole file.
+func (v *codeView) isDiff() bool {
+	return v.hunks != nil
+}
+
+// changedLines is the number of added lines, or the file length when the
+// view shows the whole file.
+func (v *codeView) changedLines() int {
+	if v.isDiff() {
+		return len(v.added)
+	}
+	return len(v.lines)
+}
+
+// windows returns the merged new-file line ranges of the hunks widened by
+// contextLines.
+func windows(hunks []diff.Hunk, contextLines, total int) []lineRange {
+	var result []lineRange
+	for _, h := range hunks {
+		start := h.NewStart - contextLines
+		end := h.NewStart + h.NewLines - 1 + contextLines
+		if h.NewLines == 0 {
+			end = h.NewStart + contextLines
+		}
+		if start < 1 {
+			start = 1
+		}
+		if end > total {
+			end = total
+		}
+		if len(result) > 0 && start <= result[len(result)-1].end+1 {
+			if end > result[len(result)-1].end {
+				result[len(result)-1].end = end
+			}
+			continue
+		}
+		result = append(result, lineRange{start, end})
+	}
+	return result
+}
+
+// render renders the given ranges, separating them with "...".
+func (v *codeView) render(ranges []lineRange) string {
+	sections := make([]string, 0, len(ranges))
+	for _, r := range ranges {
+		sections = append(sections, v.renderRange(r))
+	}
+	return strings.Join(sections, "...\n")
+}
+
+func (v *codeView) renderRange(r lineRange) string {
+	var b strings.Builder
+	for n := r.start; n <= r.end+1; n++ {
+		for _, text := range v.removed[n] {
+			fmt.Fprintf(&b, "-       | %s\n", text)
+		}
+		if n > r.end || n > len(v.lines) {
+			break
+		}
+		fmt.Fprintf(&b, "%s%6d | %s\n", v.marker(n), n, v.lines[n-1])
+	}
+	return b.String()
+}
+
+func (v *codeView) marker(n int) string {
+	if v.added[n] {
+		return "+"
+	}
+	return " "
+}
+
+// keepInHunks drops issues reported outside the changed hunks and limits
+// the end line of the others to their hunk.
+func keepInHunks(issues []*models.Issue, hunks []diff.Hunk) []*models.Issue {
+	var kept []*models.Issue
+	for _, issue := range issues {
+		var hunk *diff.Hunk
+		for i := range hunks {
+			if hunks[i].Contains(issue.Line) {
+				hunk = &hunks[i]
+				break
+			}
+		}
+		if hunk == nil {
+			log.Printf("Dropping AI issue on %s:%d outside the changed lines", issue.Path, issue.Line)
+			continue
+		}
+		if !hunk.Contains(issue.EndLine) {
+			issue.EndLine = hunk.NewStart + hunk.NewLines - 1
+		}
+		kept = append(kept, issue)
+	}
+	return kept
+}
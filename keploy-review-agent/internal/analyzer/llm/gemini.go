@@ -0,0 +1,149 @@
+package llm
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"strings"
+)
+
+const (
+	defaultGeminiURL   = "https://generativelanguage.googleapis.com/v1beta"
+	defaultGeminiModel = "gemini-2.0-flash"
+)
+
+// GeminiProvider talks to the Google Generative Language API.
+type GeminiProvider struct {
+	baseURL    string
+	model      string
+	apiKey     string
+	httpClient *http.Client
+}
+
+func NewGeminiProvider(baseURL, model, apiKey string, client *http.Client) *GeminiProvider {
+	if baseURL == "" {
+		baseURL = defaultGeminiURL
+	}
+	if model == "" {
+		model = defaultGeminiModel
+	}
+	return &GeminiProvider{
+		baseURL:    strings.TrimSuffix(baseURL, "/"),
+		model:      model,
+		apiKey:     apiKey,
+		httpClient: client,
+	}
+}
+
+func (g *GeminiProvider) Name() string {
+	return "gemini/" + g.model
+}
+
+type geminiPart struct {
+	Text string `json:"text"`
+}
+
+type geminiContent struct {
+	Role  string       `json:"role,omitempty"`
+	Parts []geminiPart `json:"parts"`
+}
+
+// contents converts messages to Gemini contents. System messages become the
+// system instruction and the assistant role is called "model".
+func (g *GeminiProvider) contents(messages []Message) (*geminiContent, []geminiContent) {
+	var system *geminiContent
+	var contents []geminiContent
+
+	for _, m := range messages {
+		switch m.Role {
+		case RoleSystem:
+			if system == nil {
+				system = &geminiContent{}
+			}
+			system.Parts = append(system.Parts, geminiPart{Text: m.Content})
+		case RoleAssistant:
+			contents = append(contents, geminiContent{Role: "model", Parts: []geminiPart{{Text: m.Content}}})
+		default:
+			contents = append(contents, geminiContent{Role: "user", Parts: []geminiPart{{Text: m.Content}}})
+		}
+	}
+	return system, contents
+}
+
+func (g *GeminiProvider) Generate(ctx context.Context, req *Request) (*Response, error) {
+	system, contents := g.contents(req.Messages)
+
+	generationConfig := map[string]interface{}{
+		"temperature":     req.Temperature,
+		"maxOutputTokens": req.MaxTokens,
+	}
+	if len(req.Schema) > 0 {
+		generationConfig["responseMimeType"] = "application/json"
+		generationConfig["responseSchema"] = req.Schema
+	}
+
+	requestBody := map[string]interface{}{
+		"contents":         contents,
+		"generationConfig": generationConfig,
+		"safetySettings": []map[string]interface{}{
+			{
+				"category":  "HARM_CATEGORY_DANGEROUS_CONTENT",
+				"threshold": "BLOCK_ONLY_HIGH",
+			},
+		},
+	}
+	if system != nil {
+		requestBody["systemInstruction"] = system
+	}
+
+	var response struct {
+		Candidates []struct {
+			Content geminiContent `json:"content"`
+		} `json:"candidates"`
+		UsageMetadata struct {
+			PromptTokenCount     int `json:"promptTokenCount"`
+			CandidatesTokenCount int `json:"candidatesTokenCount"`
+		} `json:"usageMetadata"`
+	}
+	if err := postJSON(ctx, g.httpClient, g.endpoint("generateContent"), g.headers(), requestBody, &response); err != nil {
+		return nil, err
+	}
+
+	if len(response.Candidates) == 0 || len(response.Candidates[0].Content.Parts) == 0 {
+		return nil, fmt.Errorf("no content in response")
+	}
+
+	var text strings.Builder
+	for _, part := range response.Candidates[0].Content.Parts {
+		text.WriteString(part.Text)
+	}
+	return &Response{
+		Text:         text.String(),
+		InputTokens:  response.UsageMetadata.PromptTokenCount,
+		OutputTokens: response.UsageMetadata.CandidatesTokenCount,
+	}, nil
+}
+
+func (g *GeminiProvider) CountTokens(ctx context.Context, messages []Message) (int, error) {
+	system, contents := g.contents(messages)
+	if system != nil {
+		// countTokens has no system instruction field; count it as content.
+		contents = append([]geminiContent{{Role: "user", Parts: system.Parts}}, contents...)
+	}
+
+	var response struct {
+		TotalTokens int `json:"totalTokens"`
+	}
+	if err := postJSON(ctx, g.httpClient, g.endpoint("countTokens"), g.headers(), map[string]interface{}{"contents": contents}, &response); err != nil {
+		return 0, err
+	}
+	return response.TotalTokens, nil
+}
+
+func (g *GeminiProvider) endpoint(method string) string {
+	return fmt.Sprintf("%s/models/%s:%s", g.baseURL, g.model, method)
+}
+
+func (g *GeminiProvider) headers() map[string]string {
+	return map[string]string{"x-goog-api-key": g.apiKey}
+}
@@ -0,0 +1,180 @@
+package llm
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"strings"
+	"sync"
+)
+
+const (
+	defaultOllamaURL = "http://localhost:11434"
+
+	// defaultOllamaContext is assumed when the server does not report the
+	// context length of the model.
+	defaultOllamaContext = 4096
+)
+
+// ErrContextWindowExceeded is returned when a request does not fit in the
+// context window of the model.
+var ErrContextWindowExceeded = errors.New("context window exceeded")
+
+// OllamaProvider talks to a locally hosted Ollama server, so reviews can run
+// without sending source code to an external API.
+type OllamaProvider struct {
+	baseURL    string
+	model      string
+	httpClient *http.Client
+
+	mu            sync.Mutex
+	contextWindow int // configured or discovered window, 0 until known
+}
+
+// NewOllamaProvider returns a provider for model. A contextWindow of 0 uses
+// the context length reported by the server for the model.
+func NewOllamaProvider(baseURL, model string, contextWindow int, client *http.Client) *OllamaProvider {
+	if baseURL == "" {
+		baseURL = defaultOllamaURL
+	}
+	return &OllamaProvider{
+		baseURL:       strings.TrimSuffix(baseURL, "/"),
+		model:         model,
+		httpClient:    client,
+		contextWindow: contextWindow,
+	}
+}
+
+func (o *OllamaProvider) Name() string {
+	return "ollama/" + o.model
+}
+
+// ContextWindow returns the context length of the model in tokens. The
+// length is looked up once; when the server does not report it, the default
+// is assumed and kept too, so later requests do not ask again.
+func (o *OllamaProvider) ContextWindow(ctx context.Context) int {
+	o.mu.Lock()
+	window := o.contextWindow
+	o.mu.Unlock()
+	if window > 0 {
+		return window
+	}
+
+	window = defaultOllamaContext
+	var response struct {
+		ModelInfo map[string]interface{} `json:"model_info"`
+	}
+	if err := postJSON(ctx, o.httpClient, o.baseURL+"/api/show", nil, map[string]string{"model": o.model}, &response); err != nil {
+		log.Printf("Warning: Failed to read the context length of %s, assuming %d tokens: %v", o.model, defaultOllamaContext, err)
+	} else {
+		for key, value := range response.ModelInfo {
+			if length, ok := value.(float64); ok && length > 0 && strings.HasSuffix(key, ".context_length") {
+				window = int(length)
+				break
+			}
+		}
+	}
+
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	if o.contextWindow == 0 {
+		o.contextWindow = window
+	}
+	return o.contextWindow
+}
+
+type ollamaChunk struct {
+	Message struct {
+		Content string `json:"content"`
+	} `json:"message"`
+	Done            bool   `json:"done"`
+	Error           string `json:"error"`
+	PromptEvalCount int    `json:"prompt_eval_count"`
+	EvalCount       int    `json:"eval_count"`
+}
+
+// Generate streams the answer of the model. The context size is sized to the
+// request, since Ollama otherwise silently truncates prompts longer than its
+// default context.
+func (o *OllamaProvider) Generate(ctx context.Context, req *Request) (*Response, error) {
+	window := o.ContextWindow(ctx)
+	needed := estimateMessageTokens(req.Messages) + req.MaxTokens
+	if needed > window {
+		return nil, fmt.Errorf("%w: about %d tokens needed, %s has %d", ErrContextWindowExceeded, needed, o.model, window)
+	}
+
+	numCtx := (needed/1024 + 1) * 1024
+	if numCtx > window {
+		numCtx = window
+	}
+
+	messages := make([]openAIMessage, 0, len(req.Messages))
+	for _, m := range req.Messages {
+		messages = append(messages, openAIMessage{Role: m.Role, Content: m.Content})
+	}
+
+	requestBody := map[string]interface{}{
+		"model":    o.model,
+		"messages": messages,
+		"stream":   true,
+		"options": map[string]interface{}{
+			"temperature": req.Temperature,
+			"num_predict": req.MaxTokens,
+			"num_ctx":     numCtx,
+		},
+	}
+	if len(req.Schema) > 0 {
+		requestBody["format"] = req.Schema
+	}
+
+	resp, err := sendJSON(ctx, o.httpClient, o.baseURL+"/api/chat", nil, requestBody)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	var text strings.Builder
+	result := &Response{}
+
+	scanner := bufio.NewScanner(resp.Body)
+	scanner.Buffer(make([]byte, 64*1024), 1024*1024)
+	for scanner.Scan() {
+		line := scanner.Bytes()
+		if len(strings.TrimSpace(string(line))) == 0 {
+			continue
+		}
+
+		var chunk ollamaChunk
+		if err := json.Unmarshal(line, &chunk); err != nil {
+			return nil, fmt.Errorf("failed to decode stream chunk: %w", err)
+		}
+		if chunk.Error != "" {
+			return nil, fmt.Errorf("model error: %s", chunk.Error)
+		}
+
+		text.WriteString(chunk.Message.Content)
+		if chunk.Done {
+			result.InputTokens = chunk.PromptEvalCount
+			result.OutputTokens = chunk.EvalCount
+			break
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read response stream: %w", err)
+	}
+
+	if text.Len() == 0 {
+		return nil, fmt.Errorf("no content in response")
+	}
+	result.Text = text.String()
+	return result, nil
+}
+
+// CountTokens estimates the token count, as Ollama has no tokenizer endpoint.
+func (o *OllamaProvider) CountTokens(ctx context.Context, messages []Message) (int, error) {
+	return estimateMessageTokens(messages), nil
+}
@@ -0,0 +1,43 @@
+package llm
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+)
+
+func TestOllamaContextWindow(t *testing.T) {
+	tests := []struct {
+		name   string
+		status int
+		body   string
+		want   int
+	}{
+		{"reported", http.StatusOK, `{"model_info": {"llama.context_length": 8192}}`, 8192},
+		{"not reported", http.StatusOK, `{"model_info": {}}`, defaultOllamaContext},
+		{"server error", http.StatusInternalServerError, `{"error": "boom"}`, defaultOllamaContext},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var calls atomic.Int32
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				calls.Add(1)
+				w.WriteHeader(tt.status)
+				w.Write([]byte(tt.body))
+			}))
+			defer server.Close()
+
+			provider := NewOllamaProvider(server.URL, "llama", 0, server.Client())
+			for i := 0; i < 3; i++ {
+				if got := provider.ContextWindow(context.Background()); got != tt.want {
+					t.Errorf("ContextWindow() = %d, want %d", got, tt.want)
+				}
+			}
+			if n := calls.Load(); n != 1 {
+				t.Errorf("ContextWindow() asked the server %d times, want once", n)
+			}
+		})
+	}
+}
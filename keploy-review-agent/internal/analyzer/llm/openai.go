@@ -0,0 +1,98 @@
+package llm
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"strings"
+)
+
+const defaultOpenAIURL = "https://api.openai.com/v1"
+
+// OpenAIProvider talks to any OpenAI-compatible chat completions API, such
+// as OpenAI itself, vLLM, llama.cpp server or LocalAI.
+type OpenAIProvider struct {
+	baseURL    string
+	model      string
+	apiKey     string
+	httpClient *http.Client
+}
+
+func NewOpenAIProvider(baseURL, model, apiKey string, client *http.Client) *OpenAIProvider {
+	if baseURL == "" {
+		baseURL = defaultOpenAIURL
+	}
+	return &OpenAIProvider{
+		baseURL:    strings.TrimSuffix(baseURL, "/"),
+		model:      model,
+		apiKey:     apiKey,
+		httpClient: client,
+	}
+}
+
+func (o *OpenAIProvider) Name() string {
+	return "openai/" + o.model
+}
+
+type openAIMessage struct {
+	Role    string `json:"role"`
+	Content string `json:"content"`
+}
+
+func (o *OpenAIProvider) Generate(ctx context.Context, req *Request) (*Response, error) {
+	messages := make([]openAIMessage, 0, len(req.Messages))
+	for _, m := range req.Messages {
+		messages = append(messages, openAIMessage{Role: m.Role, Content: m.Content})
+	}
+
+	requestBody := map[string]interface{}{
+		"model":       o.model,
+		"messages":    messages,
+		"temperature": req.Temperature,
+		"max_tokens":  req.MaxTokens,
+	}
+	if len(req.Schema) > 0 {
+		requestBody["response_format"] = map[string]interface{}{
+			"type": "json_schema",
+			"json_schema": map[string]interface{}{
+				"name":   "response",
+				"schema": req.Schema,
+			},
+		}
+	}
+
+	var response struct {
+		Choices []struct {
+			Message openAIMessage `json:"message"`
+		} `json:"choices"`
+		Usage struct {
+			PromptTokens     int `json:"prompt_tokens"`
+			CompletionTokens int `json:"completion_tokens"`
+		} `json:"usage"`
+	}
+	if err := postJSON(ctx, o.httpClient, o.baseURL+"/chat/completions", o.headers(), requestBody, &response); err != nil {
+		return nil, err
+	}
+
+	if len(response.Choices) == 0 {
+		return nil, fmt.Errorf("no content in response")
+	}
+	return &Response{
+		Text:         response.Choices[0].Message.Content,
+		InputTokens:  response.Usage.PromptTokens,
+		OutputTokens: response.Usage.CompletionTokens,
+	}, nil
+}
+
+// CountTokens estimates the token count, as the chat completions API has no
+// tokenizer endpoint.
+func (o *OpenAIProvider) CountTokens(ctx context.Context, messages []Message) (int, error) {
+	return estimateMessageTokens(messages), nil
+}
+
+func (o *OpenAIProvider) headers() map[string]string {
+	if o.apiKey == "" {
+		return nil
+	}
+	return map[string]string{"Authorization": "Bearer " + o.apiKey}
+}
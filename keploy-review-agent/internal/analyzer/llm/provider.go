@@ -0,0 +1,151 @@
+package llm
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"time"
+
+	"github.com/keploy/keploy-review-agent/internal/config"
+)
+
+// Message roles.
+const (
+	RoleSystem    = "system"
+	RoleUser      = "user"
+	RoleAssistant = "assistant"
+)
+
+const providerTimeout = 30 * time.Second
+
+type Message struct {
+	Role    string
+	Content string
+}
+
+// Request is a single generation request. When Schema is set, the provider
+// is asked to answer with JSON matching it.
+type Request struct {
+	Messages    []Message
+	Schema      json.RawMessage
+	MaxTokens   int
+	Temperature float64
+}
+
+type Response struct {
+	Text         string
+	InputTokens  int
+	OutputTokens int
+}
+
+// LLMProvider is a backend able to generate text from a conversation.
+type LLMProvider interface {
+	// Name identifies the provider and model, for example "gemini/gemini-2.0-flash".
+	Name() string
+	Generate(ctx context.Context, req *Request) (*Response, error)
+	// CountTokens returns the number of input tokens the messages use.
+	CountTokens(ctx context.Context, messages []Message) (int, error)
+}
+
+// contextWindower is implemented by providers that know the context length
+// of their model, so prompts can be sized to fit in it.
+type contextWindower interface {
+	ContextWindow(ctx context.Context) int
+}
+
+// NewProvider returns the provider selected by the configuration.
+func NewProvider(cfg *config.Config) (LLMProvider, error) {
+	client := &http.Client{Timeout: providerTimeout}
+
+	switch cfg.LLMProvider {
+	case config.LLMProviderGemini, "":
+		return NewGeminiProvider(cfg.LLMProviderURL, cfg.LLMModel, cfg.LLMApiKey, client), nil
+	case config.LLMProviderOpenAI:
+		return NewOpenAIProvider(cfg.LLMProviderURL, cfg.LLMModel, cfg.LLMApiKey, client), nil
+	case config.LLMProviderOllama:
+		// Local models can take minutes to answer; the request context bounds
+		// the call instead of a client timeout.
+		return NewOllamaProvider(cfg.LLMProviderURL, cfg.LLMModel, cfg.LLMContextWindow, &http.Client{}), nil
+	default:
+		return nil, fmt.Errorf("unknown LLM provider %q", cfg.LLMProvider)
+	}
+}
+
+// EstimateTokens approximates the token count of text for providers without
+// a tokenizer endpoint, at roughly four characters per token.
+func EstimateTokens(text string) int {
+	return (len(text) + 3) / 4
+}
+
+func estimateMessageTokens(messages []Message) int {
+	total := 0
+	for _, m := range messages {
+		total += EstimateTokens(m.Content) + 4 // role and separators
+	}
+	return total
+}
+
+// APIError is returned when a provider responds with a non-success status.
+type APIError struct {
+	StatusCode int
+	Body       string
+	RetryAfter time.Duration // from the Retry-After header, 0 when absent
+}
+
+func (e *APIError) Error() string {
+	return fmt.Sprintf("API error %d: %s", e.StatusCode, e.Body)
+}
+
+// postJSON sends payload as JSON and decodes the response into v.
+func postJSON(ctx context.Context, client *http.Client, url string, headers map[string]string, payload, v interface{}) error {
+	resp, err := sendJSON(ctx, client, url, headers, payload)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if err := json.NewDecoder(resp.Body).Decode(v); err != nil {
+		return fmt.Errorf("failed to decode response: %w", err)
+	}
+	return nil
+}
+
+// sendJSON sends payload as JSON and returns the response of a successful
+// request. The caller closes the response body.
+func sendJSON(ctx context.Context, client *http.Client, url string, headers map[string]string, payload interface{}) (*http.Response, error) {
+	body, err := json.Marshal(payload)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode request: %w", err)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, url, bytes.NewReader(body))
+	if err != nil {
+		return nil, fmt.Errorf("failed to create request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	for name, value := range headers {
+		req.Header.Set(name, value)
+	}
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("API request failed: %w", err)
+	}
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		defer resp.Body.Close()
+		return nil, &APIError{
+			StatusCode: resp.StatusCode,
+			Body:       readBody(resp),
+			RetryAfter: parseRetryAfter(resp.Header.Get("Retry-After")),
+		}
+	}
+	return resp, nil
+}
+
+func readBody(resp *http.Response) string {
+	body, _ := io.ReadAll(resp.Body)
+	return string(body)
+}
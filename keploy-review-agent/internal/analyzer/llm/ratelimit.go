@@ -0,0 +1,157 @@
+package llm
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// Limiter is a token-bucket rate limiter with a requests-per-minute and a
+// tokens-per-minute bucket. It is shared by every review using the same
+// provider, so concurrent reviews together stay within the provider quota.
+type Limiter struct {
+	mu       sync.Mutex
+	requests *bucket
+	tokens   *bucket
+}
+
+// NewLimiter returns a limiter for rpm requests and tpm tokens per minute.
+// A limit of 0 or less is unlimited.
+func NewLimiter(rpm, tpm int) *Limiter {
+	now := time.Now()
+	return &Limiter{
+		requests: newBucket(rpm, now),
+		tokens:   newBucket(tpm, now),
+	}
+}
+
+// SetRates changes the limits to rpm requests and tpm tokens per minute,
+// keeping what was already used from the current buckets. It applies a
+// reloaded configuration to reviews already waiting on the limiter.
+func (l *Limiter) SetRates(rpm, tpm int) {
+	if l == nil {
+		return
+	}
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	now := time.Now()
+	l.requests = l.requests.resize(rpm, now)
+	l.tokens = l.tokens.resize(tpm, now)
+}
+
+type bucket struct {
+	capacity float64
+	level    float64
+	rate     float64 // per second
+	updated  time.Time
+}
+
+func newBucket(perMinute int, now time.Time) *bucket {
+	if perMinute <= 0 {
+		return nil
+	}
+	return &bucket{
+		capacity: float64(perMinute),
+		level:    float64(perMinute),
+		rate:     float64(perMinute) / 60,
+		updated:  now,
+	}
+}
+
+// resize returns the bucket with a new limit, spending from it what was
+// spent from b.
+func (b *bucket) resize(perMinute int, now time.Time) *bucket {
+	if b == nil || perMinute <= 0 {
+		return newBucket(perMinute, now)
+	}
+	b.refill(now)
+	spent := b.capacity - b.level
+	b.capacity = float64(perMinute)
+	b.rate = float64(perMinute) / 60
+	b.level = b.capacity - spent
+	return b
+}
+
+func (b *bucket) refill(now time.Time) {
+	b.level += now.Sub(b.updated).Seconds() * b.rate
+	if b.level > b.capacity {
+		b.level = b.capacity
+	}
+	b.updated = now
+}
+
+// delay returns how long to wait until n can be taken from the bucket.
+func (b *bucket) delay(n float64) time.Duration {
+	if n > b.capacity {
+		n = b.capacity // larger requests wait for a full bucket
+	}
+	if b.level >= n {
+		return 0
+	}
+	return time.Duration((n - b.level) / b.rate * float64(time.Second))
+}
+
+// Wait blocks until a request using the given number of tokens is allowed,
+// or ctx is done.
+func (l *Limiter) Wait(ctx context.Context, tokens int) error {
+	if l == nil {
+		return nil
+	}
+
+	for {
+		l.mu.Lock()
+		now := time.Now()
+		var wait time.Duration
+		for _, b := range []struct {
+			b *bucket
+			n float64
+		}{{l.requests, 1}, {l.tokens, float64(tokens)}} {
+			if b.b == nil {
+				continue
+			}
+			b.b.refill(now)
+			if d := b.b.delay(b.n); d > wait {
+				wait = d
+			}
+		}
+		if wait == 0 {
+			if l.requests != nil {
+				l.requests.level--
+			}
+			if l.tokens != nil {
+				l.tokens.level -= float64(tokens)
+			}
+			l.mu.Unlock()
+			return nil
+		}
+		l.mu.Unlock()
+
+		if err := sleep(ctx, wait); err != nil {
+			return err
+		}
+	}
+}
+
+// Adjust corrects the tokens taken for a request once its actual usage is
+// known.
+func (l *Limiter) Adjust(estimated, actual int) {
+	if l == nil || l.tokens == nil || actual <= 0 {
+		return
+	}
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.tokens.level -= float64(actual - estimated)
+}
+
+// sleep waits for d, returning early with the context error when ctx is done.
+func sleep(ctx context.Context, d time.Duration) error {
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-timer.C:
+		return nil
+	}
+}
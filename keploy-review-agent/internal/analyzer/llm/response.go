@@ -0,0 +1,208 @@
+package llm
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/keploy/keploy-review-agent/pkg/models"
+)
+
+// minConfidence is the confidence below which findings are discarded.
+const minConfidence = 0.7
+
+var (
+	aiCategories = []string{"security", "performance", "maintainability", "error_handling"}
+	aiSeverities = []string{"critical", "high", "medium", "low"}
+)
+
+// issuesSchema describes the JSON answer expected from the model. The root is
+// an object because some providers only accept object schemas.
+var issuesSchema = json.RawMessage(`{
+	"type": "object",
+	"properties": {
+		"issues": {
+			"type": "array",
+			"items": {
+				"type": "object",
+				"properties": {
+					"line": {"type": "integer"},
+					"end_line": {"type": "integer"},
+					"category": {"type": "string", "enum": ["security", "performance", "maintainability", "error_handling"]},
+					"description": {"type": "string"},
+					"severity": {"type": "string", "enum": ["critical", "high", "medium", "low"]},
+					"suggestion": {"type": "string"},
+					"cwe": {"type": "string"},
+					"confidence": {"type": "number"}
+				},
+				"required": ["line", "category", "description", "severity", "confidence"]
+			}
+		}
+	},
+	"required": ["issues"]
+}`)
+
+type rawIssue struct {
+	Line        *int     `json:"line"`
+	EndLine     int      `json:"end_line"`
+	Category    string   `json:"category"`
+	Description string   `json:"description"`
+	Severity    string   `json:"severity"`
+	Suggestion  string   `json:"suggestion"`
+	CWE         string   `json:"cwe"`
+	Confidence  *float64 `json:"confidence"`
+}
+
+// parseAIResponse decodes and validates the answer of the model. A response
+// that is not a valid document is an error; items that do not match the
+// schema are rejected one by one and described in the returned problems.
+func parseAIResponse(response, filePath string) ([]*models.Issue, []string, error) {
+	items, err := decodeItems(response)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	var issues []*models.Issue
+	var problems []string
+	for i, item := range items {
+		ri, err := validateItem(item)
+		if err != nil {
+			problems = append(problems, fmt.Sprintf("issues[%d]: %v", i, err))
+			continue
+		}
+		if *ri.Confidence < minConfidence {
+			continue
+		}
+		issues = append(issues, ri.toIssue(filePath))
+	}
+	return issues, problems, nil
+}
+
+// decodeItems returns the raw items of the "issues" array. A bare array is
+// accepted too, as is a document wrapped in a Markdown code fence.
+func decodeItems(response string) ([]json.RawMessage, error) {
+	text := trimFence(response)
+	if text == "" {
+		return nil, fmt.Errorf("empty response")
+	}
+
+	var items []json.RawMessage
+	if strings.HasPrefix(text, "[") {
+		if err := json.Unmarshal([]byte(text), &items); err != nil {
+			return nil, fmt.Errorf("invalid JSON: %w", err)
+		}
+		return items, nil
+	}
+
+	var doc struct {
+		Issues *[]json.RawMessage `json:"issues"`
+	}
+	if err := json.Unmarshal([]byte(text), &doc); err != nil {
+		return nil, fmt.Errorf("invalid JSON: %w", err)
+	}
+	if doc.Issues == nil {
+		return nil, fmt.Errorf(`missing "issues" array`)
+	}
+	return *doc.Issues, nil
+}
+
+// trimFence removes surrounding whitespace and a Markdown code fence.
+func trimFence(response string) string {
+	text := strings.TrimSpace(response)
+	if strings.HasPrefix(text, "```") {
+		text = strings.TrimPrefix(text, "```json")
+		text = strings.TrimPrefix(text, "```")
+		text = strings.TrimSuffix(strings.TrimSpace(text), "```")
+		text = strings.TrimSpace(text)
+	}
+	return text
+}
+
+func validateItem(item json.RawMessage) (*rawIssue, error) {
+	var ri rawIssue
+	if err := json.Unmarshal(item, &ri); err != nil {
+		return nil, fmt.Errorf("invalid item: %v", err)
+	}
+
+	ri.Category = strings.ToLower(strings.TrimSpace(ri.Category))
+	ri.Severity = strings.ToLower(strings.TrimSpace(ri.Severity))
+
+	switch {
+	case ri.Line == nil:
+		return nil, fmt.Errorf(`missing "line"`)
+	case *ri.Line < 1:
+		return nil, fmt.Errorf("line %d must be positive", *ri.Line)
+	case ri.EndLine != 0 && ri.EndLine < *ri.Line:
+		return nil, fmt.Errorf("end_line %d is before line %d", ri.EndLine, *ri.Line)
+	case !contains(aiCategories, ri.Category):
+		return nil, fmt.Errorf("category %q must be one of %s", ri.Category, strings.Join(aiCategories, ", "))
+	case !contains(aiSeverities, ri.Severity):
+		return nil, fmt.Errorf("severity %q must be one of %s", ri.Severity, strings.Join(aiSeverities, ", "))
+	case strings.TrimSpace(ri.Description) == "":
+		return nil, fmt.Errorf(`missing "description"`)
+	case ri.Confidence == nil:
+		return nil, fmt.Errorf(`missing "confidence"`)
+	case *ri.Confidence < 0 || *ri.Confidence > 1:
+		return nil, fmt.Errorf("confidence %v must be between 0 and 1", *ri.Confidence)
+	}
+	return &ri, nil
+}
+
+func (ri *rawIssue) toIssue(filePath string) *models.Issue {
+	var tags []string
+	if cwe := strings.ToUpper(strings.TrimSpace(ri.CWE)); strings.HasPrefix(cwe, "CWE-") {
+		tags = append(tags, cwe)
+	}
+	endLine := ri.EndLine
+	if endLine < *ri.Line {
+		endLine = *ri.Line
+	}
+
+	return &models.Issue{
+		Path:        filePath,
+		Line:        *ri.Line,
+		EndLine:     endLine,
+		RuleID:      "ai/" + ri.Category,
+		Category:    ri.Category,
+		Confidence:  *ri.Confidence,
+		Title:       fmt.Sprintf("[%s] %s", strings.ToUpper(ri.Category), ri.Description),
+		Description: ri.Description,
+		Severity:    mapSeverity(ri.Severity),
+		Suggestion:  ri.Suggestion,
+		Tags:        tags,
+		Source:      "AI Analysis",
+	}
+}
+
+// repairPrompt asks the model to correct an answer that failed validation.
+func repairPrompt(problems []string) string {
+	return fmt.Sprintf(`Your previous answer did not match the required JSON format:
+- %s
+
+Answer again with only a JSON object of the form {"issues": [...]}, where every issue has an
+integer "line", an optional integer "end_line" not before "line", a "category" of %s,
+a "severity" of %s, a non-empty "description" and a "confidence" between 0 and 1.`,
+		strings.Join(problems, "\n- "), strings.Join(aiCategories, "|"), strings.Join(aiSeverities, "|"))
+}
+
+func contains(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
+
+func mapSeverity(s string) models.Severity {
+	switch strings.ToLower(s) {
+	case "critical":
+		return models.SeverityCritical
+	case "high":
+		return models.SeverityError
+	case "medium":
+		return models.SeverityWarning
+	default:
+		return models.SeverityInfo
+	}
+}
@@ -0,0 +1,55 @@
+package llm
+
+import (
+	"errors"
+	"math/rand"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+const (
+	maxRetries = 3
+	baseDelay  = 1 * time.Second
+	maxDelay   = 30 * time.Second
+)
+
+// retryDelay reports whether a failed request should be retried and how
+// long to wait first. Only rate limiting (429) and server errors (5xx) are
+// retried; a Retry-After header takes precedence over exponential backoff.
+func retryDelay(err error, attempt int) (time.Duration, bool) {
+	var apiErr *APIError
+	if !errors.As(err, &apiErr) {
+		return 0, false
+	}
+	if apiErr.StatusCode != http.StatusTooManyRequests && apiErr.StatusCode < 500 {
+		return 0, false
+	}
+	if apiErr.RetryAfter > 0 {
+		return apiErr.RetryAfter, true
+	}
+
+	delay := baseDelay << attempt
+	if delay > maxDelay {
+		delay = maxDelay
+	}
+	// Jitter spreads out the retries of concurrent workers.
+	return delay/2 + time.Duration(rand.Int63n(int64(delay/2)+1)), true
+}
+
+// parseRetryAfter parses a Retry-After header given in seconds or as an
+// HTTP date.
+func parseRetryAfter(value string) time.Duration {
+	if value == "" {
+		return 0
+	}
+	if seconds, err := strconv.Atoi(value); err == nil && seconds > 0 {
+		return time.Duration(seconds) * time.Second
+	}
+	if at, err := http.ParseTime(value); err == nil {
+		if d := time.Until(at); d > 0 {
+			return d
+		}
+	}
+	return 0
+}
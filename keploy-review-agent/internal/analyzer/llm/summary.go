@@ -0,0 +1,150 @@
+package llm
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+	"strings"
+
+	"github.com/keploy/keploy-review-agent/pkg/models"
+)
+
+// summaryBudgetChunks is the size of the diff sent for a walkthrough, in
+// chunks of the configured chunk size. Files beyond it are listed by name.
+const summaryBudgetChunks = 4
+
+// defaultSummaryTokens bounds the diff sent for a walkthrough when files are
+// not chunked.
+const defaultSummaryTokens = 24000
+
+var summarySchema = json.RawMessage(`{
+	"type": "object",
+	"properties": {
+		"overview": {"type": "string"},
+		"files": {
+			"type": "array",
+			"items": {
+				"type": "object",
+				"properties": {
+					"path": {"type": "string"},
+					"summary": {"type": "string"}
+				},
+				"required": ["path", "summary"]
+			}
+		},
+		"risks": {"type": "array", "items": {"type": "string"}},
+		"test_focus": {"type": "array", "items": {"type": "string"}}
+	},
+	"required": ["overview", "files", "risks", "test_focus"]
+}`)
+
+// Summarize writes a walkthrough of a pull request from its title,
+// description and the diff of its files.
+func (g *Analyzer) Summarize(ctx context.Context, title, body string, files []*models.File) (*models.Walkthrough, error) {
+	usage := &tokenUsage{}
+	messages := []Message{{Role: RoleUser, Content: g.summaryPrompt(title, body, files)}}
+
+	response, err := g.complete(ctx, messages, summarySchema, usage)
+	if err != nil {
+		return nil, err
+	}
+	walkthrough, err := parseWalkthrough(response.Text)
+	if err != nil {
+		log.Printf("AI walkthrough failed validation, asking for a repair: %v", err)
+		messages = append(messages,
+			Message{Role: RoleAssistant, Content: response.Text},
+			Message{Role: RoleUser, Content: summaryRepairPrompt(err)},
+		)
+		response, err = g.complete(ctx, messages, summarySchema, usage)
+		if err != nil {
+			return nil, err
+		}
+		if walkthrough, err = parseWalkthrough(response.Text); err != nil {
+			return nil, fmt.Errorf("invalid walkthrough after repair: %w", err)
+		}
+	}
+
+	log.Printf("AI walkthrough used %d input and %d output tokens", usage.input, usage.output)
+	return walkthrough, nil
+}
+
+func (g *Analyzer) summaryPrompt(title, body string, files []*models.File) string {
+	budget := defaultSummaryTokens
+	if g.config.ChunkTokens > 0 {
+		budget = summaryBudgetChunks * g.config.ChunkTokens
+	}
+
+	var diff strings.Builder
+	var omitted []string
+	for _, file := range files {
+		patch := file.Patch
+		if patch == "" {
+			patch = "(no diff available)"
+		}
+		section := fmt.Sprintf("--- %s\n%s\n", file.Path, strings.TrimRight(patch, "\n"))
+		if EstimateTokens(diff.String()+section) > budget {
+			omitted = append(omitted, file.Path)
+			continue
+		}
+		diff.WriteString(section)
+	}
+	if len(omitted) > 0 {
+		fmt.Fprintf(&diff, "\nOther changed files, diff omitted for length:\n- %s\n", strings.Join(omitted, "\n- "))
+	}
+
+	prompt := fmt.Sprintf(`Write a walkthrough of this pull request for its reviewers.
+
+Title: %s
+
+Description:
+%s
+
+Diff:
+%s
+Respond with only JSON in this format:
+{
+	"overview": "<two to four sentences on what the pull request changes and why>",
+	"files": [{"path": "<file path>", "summary": "<one sentence on the change in this file>"}],
+	"risks": ["<area where the change could break behavior or needs careful review>"],
+	"test_focus": ["<behavior reviewers or testers should verify>"]
+}
+
+Rules:
+1. Describe what the code does, not how the diff looks
+2. List every changed file once, using the paths shown above
+3. Leave "risks" or "test_focus" empty rather than inventing items`, title, strings.TrimSpace(body), diff.String())
+
+	if g.config.PromptAdditions != "" {
+		prompt += "\n\nAdditional instructions for this repository:\n" + g.config.PromptAdditions
+	}
+	return prompt
+}
+
+func parseWalkthrough(response string) (*models.Walkthrough, error) {
+	text := trimFence(response)
+	if text == "" {
+		return nil, fmt.Errorf("empty response")
+	}
+
+	var w models.Walkthrough
+	if err := json.Unmarshal([]byte(text), &w); err != nil {
+		return nil, fmt.Errorf("invalid JSON: %w", err)
+	}
+	if strings.TrimSpace(w.Overview) == "" {
+		return nil, fmt.Errorf(`missing "overview"`)
+	}
+	for i, f := range w.Files {
+		if strings.TrimSpace(f.Path) == "" || strings.TrimSpace(f.Summary) == "" {
+			return nil, fmt.Errorf(`files[%d]: missing "path" or "summary"`, i)
+		}
+	}
+	return &w, nil
+}
+
+func summaryRepairPrompt(err error) string {
+	return fmt.Sprintf(`Your previous answer did not match the required JSON format: %v
+
+Answer again with only a JSON object with a non-empty "overview" string, a "files" array of
+objects with non-empty "path" and "summary" strings, and "risks" and "test_focus" arrays of strings.`, err)
+}
@@ -2,9 +2,12 @@ package analyzer
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"log"
 	"os"
+	"sort"
+	"strings"
 	"sync"
 	"time"
 
@@ -12,16 +15,20 @@ import (
 	"github.com/keploy/keploy-review-agent/internal/analyzer/dependency"
 	"github.com/keploy/keploy-review-agent/internal/analyzer/llm"
 	"github.com/keploy/keploy-review-agent/internal/analyzer/static"
+	"github.com/keploy/keploy-review-agent/internal/cache"
 	"github.com/keploy/keploy-review-agent/internal/config"
+	"github.com/keploy/keploy-review-agent/internal/directive"
+	"github.com/keploy/keploy-review-agent/internal/feedback"
 	"github.com/keploy/keploy-review-agent/internal/formatter"
+	"github.com/keploy/keploy-review-agent/internal/gate"
+	"github.com/keploy/keploy-review-agent/internal/glob"
+	"github.com/keploy/keploy-review-agent/internal/repoconfig"
 	"github.com/keploy/keploy-review-agent/internal/reporter"
-	"github.com/keploy/keploy-review-agent/internal/shared"
 	"github.com/keploy/keploy-review-agent/pkg/github"
 	"github.com/keploy/keploy-review-agent/pkg/models"
 )
 
 var pullnumber int
-var AllIssues []*models.Issue
 
 func PullRequestNumber(currentpullnumber int) int {
 	pullnumber = currentpullnumber
@@ -33,124 +40,323 @@ type Job struct {
 	RepoOwner string
 	RepoName  string
 	PRNumber  int
+	HeadSHA   string
+	BaseSHA   string
+
+	Full    bool // review whole files instead of only the changed lines
+	SkipLLM bool // review without the LLM analyzer or walkthrough
+	Fork    bool // the head is in another repository; its code is never run
+}
+
+// Result is the outcome of a review.
+type Result struct {
+	Issues  []*models.Issue
+	Verdict *gate.Verdict
 }
 
+const (
+	checkRunName      = "keploy-review"
+	configErrorMarker = "<!-- keploy-review:config-error -->"
+)
+
 type Orchestrator struct {
 	cfg            *config.Config
 	staticAnalyzer *static.Linter
 	depAnalyzer    *dependency.Scanner
 	customAnalyzer *custom.Rules
-	aiAnalyzer     *llm.GoogleAIClient
+	aiAnalyzer     *llm.Analyzer // nil when no LLM provider is available
 	githubClient   *github.Client
+	gates          *gate.File
+	cache          *cache.Cache    // nil when caching is disabled
+	feedbackStore  *feedback.Store // nil when feedback is disabled
 }
 
 func NewOrchestrator(cfg *config.Config) *Orchestrator {
+	return NewOrchestratorWithLimiter(cfg, llm.NewLimiter(cfg.LLMRequestsPerMinute, cfg.LLMTokensPerMinute))
+}
+
+// NewOrchestratorWithLimiter returns an orchestrator whose LLM requests go
+// through limiter, which orchestrators of successive configurations share so
+// that a reload does not reset the provider quota.
+func NewOrchestratorWithLimiter(cfg *config.Config, limiter *llm.Limiter) *Orchestrator {
 	aiConfig := &llm.AIConfig{
-		MaxTokens:   cfg.AIMaxTokens,
-		Temperature: cfg.AITemperature,
-		MinSeverity: models.SeverityInfo,
+		MaxTokens:     cfg.AIMaxTokens,
+		Temperature:   cfg.AITemperature,
+		MinSeverity:   cfg.AIMinSeverity,
+		DiffContext:   cfg.LLMDiffContext,
+		ChunkTokens:   cfg.LLMChunkTokens,
+		PRTokenBudget: cfg.LLMPRTokenBudget,
+		Concurrency:   cfg.LLMConcurrency,
 	}
 
-	return &Orchestrator{
+	o := &Orchestrator{
 		cfg:            cfg,
 		staticAnalyzer: static.NewLinter(cfg),
 		depAnalyzer:    dependency.NewScanner(cfg),
 		customAnalyzer: custom.NewRules(cfg),
 		githubClient:   github.NewClient(cfg.GitHubToken),
-		aiAnalyzer:     llm.NewGoogleAIClient(cfg.GoogleAIKey, aiConfig),
 	}
+
+	if provider, err := llm.NewProvider(cfg); err != nil {
+		log.Printf("Warning: LLM analysis disabled: %v", err)
+	} else {
+		o.aiAnalyzer = llm.NewAnalyzer(provider, limiter, aiConfig)
+	}
+
+	if cfg.CacheEnabled {
+		c, err := cache.Open(cfg.CacheDir, time.Duration(cfg.CacheTTL)*time.Second, cfg.CacheMaxBytes)
+		if err != nil {
+			log.Printf("Warning: Result cache disabled: %v", err)
+		} else {
+			o.cache = c
+		}
+	}
+
+	if cfg.FeedbackEnabled {
+		store, err := feedback.Open(cfg.FeedbackDir)
+		if err != nil {
+			log.Printf("Warning: Review feedback disabled: %v", err)
+		} else {
+			o.feedbackStore = store
+		}
+	}
+
+	if cfg.QualityGateFile != "" {
+		gates, err := gate.LoadFile(cfg.QualityGateFile)
+		if err != nil {
+			log.Printf("Warning: Failed to load quality gate, failing on %s issues only: %v", cfg.FailOnSeverity, err)
+		} else {
+			o.gates = gates
+		}
+	}
+
+	return o
 }
 
-func (o *Orchestrator) AnalyzeCode(job *Job) ([]*models.Issue, error) {
+func (o *Orchestrator) AnalyzeCode(job *Job) (*Result, error) {
 	log.Printf("Starting analysis for %s/%s PR #%d", job.RepoOwner, job.RepoName, job.PRNumber)
 
-	AllIssues = []*models.Issue{}
-
 	ctx, cancel := context.WithTimeout(
 		context.Background(),
 		time.Duration(o.cfg.MaxProcessingTime)*time.Second,
 	)
 	defer cancel()
 
+	if job.Provider == "github" && (job.HeadSHA == "" || job.BaseSHA == "") {
+		if pr, err := o.githubClient.GetPullRequest(ctx, job.RepoOwner, job.RepoName, job.PRNumber); err != nil {
+			log.Printf("Warning: Failed to fetch pull request details: %v", err)
+		} else {
+			job.HeadSHA = pr.Head.Sha
+			job.BaseSHA = pr.Base.Sha
+			job.Fork = pr.FromFork()
+		}
+	}
+
+	settings := o.loadSettings(ctx, job)
+
 	files, err := o.fetchChangedFiles(ctx, job)
 	if err != nil {
 		return nil, fmt.Errorf("failed to fetch changed files: %w", err)
 	}
+	files = filterFiles(files, settings)
 	log.Printf("Fetched %d changed files", len(files))
 
+	var walkthrough sync.WaitGroup
+	if settings.Summary && !job.SkipLLM && o.aiAnalyzer != nil && job.Provider == "github" {
+		walkthrough.Add(1)
+		go func() {
+			defer walkthrough.Done()
+			if err := o.postWalkthrough(ctx, job, files, settings); err != nil {
+				log.Printf("Warning: Failed to post pull request walkthrough: %v", err)
+			}
+		}()
+	}
+	defer walkthrough.Wait()
+
+	checkout := o.newCheckout(job)
+	defer checkout.close()
+	issues, gapNotes := o.collectIssues(ctx, job, files, settings, checkout)
+	issues, notes := o.applyBaseline(ctx, job, files, settings, issues)
+	notes += gapNotes
+
+	history, err := o.fetchHistory(ctx, job)
+	if err != nil {
+		log.Printf("Warning: Failed to fetch previously posted issues: %v", err)
+	}
+	issues = history.dropDismissed(issues)
+
+	// Feedback only adjusts what is posted: findings of demoted and muted
+	// rules still count towards the quality gate.
+	verdict := settings.Gate.Evaluate(issues)
+	log.Printf("Quality gate passed: %t %v", verdict.Passed, verdict.Reasons)
+
+	tuning := o.learnFeedback(ctx, job, history, issues)
+	issues = tuning.apply(issues)
+
+	comments := o.prepareComments(issues, history.posted, settings.MaxComments)
+	o.recordPosted(job, comments, issues)
+
+	fmt.Printf("CoMMENTS are: %v\n", comments)
+	notes += tuning.report()
+	if err := o.sendReviewComment(ctx, job, issues, comments, verdict, notes); err != nil {
+		log.Printf("Warning: Failed to send review comments: %v", err)
+	}
+
+	if err := o.reportStatus(ctx, job, issues, verdict, notes); err != nil {
+		log.Printf("Warning: Failed to report review status: %v", err)
+	}
+
+	log.Printf("Analysis completed for %s/%s PR #%d with %d issues",
+		job.RepoOwner, job.RepoName, job.PRNumber, len(issues))
+	report := reporter.GenerateMarkdownReport(issues)
+
+	if err := o.saveReport(report); err != nil {
+		log.Printf("Failed to save report: %v", err)
+	}
+	fmt.Printf("GOLAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAASSSSSSSSSAAAAAAAAAAAVVVVVVVEEEEEE")
+	return &Result{Issues: issues, Verdict: verdict}, nil
+}
+
+// collectIssues runs the enabled analyzers on the files and returns their
+// fingerprinted issues, without those silenced by suppression directives or
+// below the minimum severity, and a note for the review summary on the
+// analyses that failed. Analyzers that need the whole repository use the
+// checkout.
+func (o *Orchestrator) collectIssues(ctx context.Context, job *Job, files []*models.File, settings *repoconfig.Settings,
+	checkout *checkout) ([]*models.Issue, string) {
 	resultsCh := make(chan *models.Issue)
 	var wg sync.WaitGroup
+	gaps := &analysisGaps{}
 
-
-	if o.cfg.EnableStaticAnalysis {
+	if settings.EnableStatic {
 		wg.Add(1)
 		go func() {
 			defer wg.Done()
-			o.runAnalyzer("Static", func() ([]*models.Issue, error) {
+			// Files linted in the checkout depend on the rest of the
+			// repository, such as their packages and linter
+			// configurations, so their results are only reused for the
+			// same head commit, linted as a fork or not.
+			spec := &cacheSpec{
+				analyzer: "static",
+				version:  versionOf(o.cfg.StaticAnalysisConfig, o.staticAnalyzer.ToolVersions()),
+				fileVersion: func(file *models.File) string {
+					if checkout.enabled() && static.NeedsCheckout([]*models.File{file}) {
+						return versionOf(job.HeadSHA, job.Fork)
+					}
+					return ""
+				},
+			}
+			o.runAnalyzer("Static", files, spec, func(files []*models.File) ([]*models.Issue, error) {
+				if static.NeedsCheckout(files) {
+					if dir := checkout.dir(ctx); dir != "" {
+						return o.staticAnalyzer.AnalyzeCheckout(ctx, dir, job.Fork, files)
+					}
+				}
 				return o.staticAnalyzer.Analyze(ctx, files)
-			}, resultsCh)
+			}, gaps, resultsCh)
 		}()
 	}
 
-	if o.cfg.EnableDependencyCheck {
+	tsCfg := o.cfg.StaticAnalysisConfig.TypeScriptConfig
+	if settings.EnableStatic && tsCfg.TypeScriptEnabled && tsCfg.TypeCheck {
 		wg.Add(1)
 		go func() {
 			defer wg.Done()
-			o.runAnalyzer("Dependency", func() ([]*models.Issue, error) {
+			// Type errors depend on the whole project rather than on the
+			// file they are in, so they are never cached.
+			o.runAnalyzer("Type-check", files, nil, func(files []*models.File) ([]*models.Issue, error) {
+				if !static.HasTypeScript(files) {
+					return nil, nil
+				}
+				dir := checkout.dir(ctx)
+				if dir == "" {
+					log.Printf("Warning: Skipping the type-check, which needs a checkout")
+					return nil, nil
+				}
+				return o.staticAnalyzer.TypeCheck(ctx, dir, job.Fork, files, typeCheckChangedOnly(o.cfg, job))
+			}, gaps, resultsCh)
+		}()
+	}
+
+	if settings.EnableDependency {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			// Advisories change over time; the cache TTL bounds how stale
+			// cached results can be.
+			// Whether a dependency is new depends on the diff of the manifest.
+			spec := &cacheSpec{
+				analyzer:    "dependency",
+				version:     versionOf("2", o.cfg.DependencyMinCVSS),
+				fileVersion: func(file *models.File) string { return versionOf(file.Patch) },
+			}
+			o.runAnalyzer("Dependency", files, spec, func(files []*models.File) ([]*models.Issue, error) {
 				return o.depAnalyzer.Analyze(ctx, files)
-			}, resultsCh)
+			}, gaps, resultsCh)
 		}()
 	}
 
-	if o.cfg.EnableAI {
+	if settings.EnableLLM && !job.SkipLLM && o.aiAnalyzer != nil {
+		aiAnalyzer := o.aiAnalyzerFor(settings)
+		llmFiles := files
+		if job.Full {
+			llmFiles = withoutPatches(files)
+		}
 		wg.Add(1)
 		go func() {
 			defer wg.Done()
-			o.runAnalyzer("AI", func() ([]*models.Issue, error) {
-				return o.aiAnalyzer.AnalyzeCode(ctx, files)
-			}, resultsCh)
+			spec := &cacheSpec{
+				analyzer:    "llm",
+				version:     aiAnalyzer.CacheVersion(),
+				model:       aiAnalyzer.Model(),
+				fileVersion: func(file *models.File) string { return versionOf(file.Patch) },
+			}
+			o.runAnalyzer("AI", llmFiles, spec, func(files []*models.File) ([]*models.Issue, error) {
+				return aiAnalyzer.AnalyzeCode(ctx, files)
+			}, gaps, resultsCh)
 		}()
 	}
 
-	wg.Add(1)
-	go func() {
-		defer wg.Done()
-		o.runAnalyzer("Custom", func() ([]*models.Issue, error) {
-			return o.customAnalyzer.Analyze(ctx, files)
-		}, resultsCh)
-	}()
+	if settings.EnableCustom {
+		customAnalyzer := o.customAnalyzer.WithRules(settings.CustomRules)
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			spec := &cacheSpec{analyzer: "custom", version: versionOf(settings.CustomRules)}
+			o.runAnalyzer("Custom", files, spec, func(files []*models.File) ([]*models.Issue, error) {
+				return customAnalyzer.Analyze(ctx, files)
+			}, gaps, resultsCh)
+		}()
+	}
 
 	go func() {
 		wg.Wait()
 		close(resultsCh)
 	}()
 
+	var issues []*models.Issue
 	for issue := range resultsCh {
-		AllIssues = append(AllIssues, issue)
-	}
-
-	comments := o.prepareComments(AllIssues)
-
-	fmt.Printf("CoMMENTS are: %v\n", comments)
-	if err := o.sendReviewComment(ctx, job, comments); err != nil {
-		log.Printf("Warning: Failed to send review comments: %v", err)
+		issues = append(issues, issue)
 	}
 
-	for _, issue := range AllIssues {
-		if err := shared.AddIssue(issue); err != nil {
-			log.Printf("Warning: Failed to add issue to shared storage: %v", err)
-		}
-	}
-
-	log.Printf("Analysis completed for %s/%s PR #%d with %d issues",
-		job.RepoOwner, job.RepoName, job.PRNumber, len(AllIssues))
-	report := reporter.GenerateMarkdownReport(AllIssues)
+	issues = directive.Apply(files, issues, settings.MinSeverity, unverifiedRules(settings, job, o.aiAnalyzer != nil))
+	assignFingerprints(issues, files)
+	return mergeDuplicates(issues), gaps.report()
+}
 
-	if err := o.saveReport(report); err != nil {
-		log.Printf("Failed to save report: %v", err)
-	}
-	fmt.Printf("GOLAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAASSSSSSSSSAAAAAAAAAAAVVVVVVVEEEEEE")
-	return AllIssues, nil
+// aiAnalyzerFor returns the LLM analyzer with the thresholds and prompt
+// additions of the review settings.
+func (o *Orchestrator) aiAnalyzerFor(settings *repoconfig.Settings) *llm.Analyzer {
+	return o.aiAnalyzer.WithConfig(&llm.AIConfig{
+		MaxTokens:       o.cfg.AIMaxTokens,
+		Temperature:     o.cfg.AITemperature,
+		MinSeverity:     settings.AIMinSeverity,
+		DiffContext:     o.cfg.LLMDiffContext,
+		ChunkTokens:     o.cfg.LLMChunkTokens,
+		PRTokenBudget:   o.cfg.LLMPRTokenBudget,
+		Concurrency:     o.cfg.LLMConcurrency,
+		PromptAdditions: settings.PromptAdditions,
+	})
 }
 func (o *Orchestrator) saveReport(report string) error {
 	filename := "code-analysis-report.md"
@@ -160,11 +366,33 @@ func (o *Orchestrator) saveReport(report string) error {
 	return os.WriteFile(filename, []byte(report), 0644)
 }
 
-func (o *Orchestrator) runAnalyzer(name string, analyzeFunc func() ([]*models.Issue, error), resultsCh chan<- *models.Issue) {
-	issues, err := analyzeFunc()
-	if err != nil {
-		log.Printf("%s analysis failed: %v", name, err)
-		return
+// runAnalyzer runs an analyzer on the files without cached results and
+// sends the issues to resultsCh. The files it fails to analyze are recorded
+// in gaps and never cached.
+func (o *Orchestrator) runAnalyzer(name string, files []*models.File, spec *cacheSpec,
+	analyzeFunc func([]*models.File) ([]*models.Issue, error), gaps *analysisGaps, resultsCh chan<- *models.Issue) {
+	issues, missing := o.cachedIssues(files, spec)
+	if cached := len(files) - len(missing); cached > 0 {
+		log.Printf("%s analysis reused cached results for %d of %d files", name, cached, len(files))
+	}
+
+	if len(missing) > 0 {
+		fresh, err := analyzeFunc(missing)
+		var failed []string
+		if err != nil {
+			var partial partialFailure
+			if !errors.As(err, &partial) {
+				log.Printf("%s analysis failed: %v", name, err)
+				gaps.add(name, filePaths(missing))
+				missing, fresh = nil, nil
+			} else {
+				log.Printf("%s analysis incomplete: %v", name, err)
+				failed = partial.FailedPaths()
+				gaps.add(name, failed)
+			}
+		}
+		o.storeIssues(missing, spec, fresh, failed)
+		issues = append(issues, fresh...)
 	}
 
 	log.Printf("%s analysis found %d issues", name, len(issues))
@@ -173,10 +401,82 @@ func (o *Orchestrator) runAnalyzer(name string, analyzeFunc func() ([]*models.Is
 	}
 }
 
-func (o *Orchestrator) prepareComments(issues []*models.Issue) []*models.ReviewComment {
+// analysisGaps records the files each analyzer failed to analyze, so that
+// the review says which of its results are incomplete.
+type analysisGaps struct {
+	mu     sync.Mutex
+	failed map[string][]string // paths by analyzer
+}
+
+func (g *analysisGaps) add(analyzer string, paths []string) {
+	if len(paths) == 0 {
+		return
+	}
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	if g.failed == nil {
+		g.failed = make(map[string][]string)
+	}
+	g.failed[analyzer] = append(g.failed[analyzer], paths...)
+}
+
+// report describes the failed analyses for the review summary.
+func (g *analysisGaps) report() string {
+	if len(g.failed) == 0 {
+		return ""
+	}
+	analyzers := make([]string, 0, len(g.failed))
+	for analyzer := range g.failed {
+		analyzers = append(analyzers, analyzer)
+	}
+	sort.Strings(analyzers)
+
+	var b strings.Builder
+	b.WriteString("\n\n**Incomplete analysis:** some files could not be analyzed and may have unreported issues.")
+	for _, analyzer := range analyzers {
+		paths := g.failed[analyzer]
+		sort.Strings(paths)
+		listed := paths
+		if len(listed) > maxGapPaths {
+			listed = listed[:maxGapPaths]
+		}
+		fmt.Fprintf(&b, "\n- %s analysis failed for `%s`", analyzer, strings.Join(listed, "`, `"))
+		if more := len(paths) - len(listed); more > 0 {
+			fmt.Fprintf(&b, " and %d more files", more)
+		}
+	}
+	return b.String()
+}
+
+// maxGapPaths bounds the paths listed per analyzer in the incomplete
+// analysis note.
+const maxGapPaths = 5
+
+func filePaths(files []*models.File) []string {
+	paths := make([]string, len(files))
+	for i, file := range files {
+		paths[i] = file.Path
+	}
+	return paths
+}
+
+// prepareComments formats the issues that have not been posted yet, most
+// severe first, keeping at most maxComments when the limit is positive.
+func (o *Orchestrator) prepareComments(issues []*models.Issue, posted map[string]bool, maxComments int) []*models.ReviewComment {
 	var comments []*models.ReviewComment
 
-	for _, issue := range issues {
+	ordered := append([]*models.Issue{}, issues...)
+	sort.SliceStable(ordered, func(i, j int) bool {
+		return ordered[i].Severity > ordered[j].Severity
+	})
+
+	for _, issue := range ordered {
+		if posted[issue.Fingerprint] {
+			continue
+		}
+		if maxComments > 0 && len(comments) >= maxComments {
+			break
+		}
 		comment := formatter.FormatLinterIssue(issue)
 
 		comments = append(comments, comment)
@@ -192,13 +492,178 @@ func (o *Orchestrator) fetchChangedFiles(ctx context.Context, job *Job) ([]*mode
 	return nil, fmt.Errorf("unsupported provider: %s", job.Provider)
 }
 
-func (o *Orchestrator) sendReviewComment(ctx context.Context, job *Job, comments []*models.ReviewComment) error {
+// loadSettings returns the review settings for a job: the server defaults
+// with the repository's configuration from the base branch applied. An
+// invalid configuration is reported on the pull request, if any, and ignored;
+// the report is removed once the configuration is fixed or deleted.
+func (o *Orchestrator) loadSettings(ctx context.Context, job *Job) *repoconfig.Settings {
+	settings := repoconfig.Defaults(o.cfg, o.gateFor(job))
+	if job.Provider != "github" || job.BaseSHA == "" {
+		return settings
+	}
+
+	content, err := o.githubClient.GetFileContent(ctx, job.RepoOwner, job.RepoName, repoconfig.FileName, job.BaseSHA)
+	if err != nil {
+		if !github.IsNotFound(err) {
+			log.Printf("Warning: Failed to read %s: %v", repoconfig.FileName, err)
+			return settings
+		}
+		o.clearConfigError(ctx, job)
+		return settings
+	}
+
+	rc, err := repoconfig.Parse([]byte(content))
+	if err != nil {
+		log.Printf("Warning: Ignoring repository configuration: %v", err)
+		if job.PRNumber == 0 {
+			return settings
+		}
+		body := fmt.Sprintf("⚠️ **`%s` on the base branch is invalid, so this review uses the server defaults.**\n\n```\n%s\n```",
+			repoconfig.FileName, err)
+		if err := o.githubClient.UpsertIssueComment(ctx, job.RepoOwner, job.RepoName, job.PRNumber, configErrorMarker, body); err != nil {
+			log.Printf("Warning: Failed to report configuration errors: %v", err)
+		}
+		return settings
+	}
+
+	o.clearConfigError(ctx, job)
+	settings.Apply(rc)
+	return settings
+}
+
+// clearConfigError removes the report of an invalid configuration from the
+// pull request of a job.
+func (o *Orchestrator) clearConfigError(ctx context.Context, job *Job) {
+	if job.PRNumber == 0 {
+		return
+	}
+	if err := o.githubClient.DeleteIssueComments(ctx, job.RepoOwner, job.RepoName, job.PRNumber, configErrorMarker); err != nil {
+		log.Printf("Warning: Failed to remove the configuration error comment: %v", err)
+	}
+}
+
+// unverifiedRules returns the rules whose absence from the review does not
+// prove a suppression directive unused, because their analyzer did not run.
+// Static analysis rules have no common prefix, so without it no directive can
+// be reported as unused.
+func unverifiedRules(settings *repoconfig.Settings, job *Job, llmAvailable bool) []string {
+	if !settings.EnableStatic {
+		return []string{"*"}
+	}
+	var rules []string
+	if !settings.EnableLLM || job.SkipLLM || !llmAvailable {
+		rules = append(rules, "ai")
+	}
+	if !settings.EnableCustom {
+		rules = append(rules, "custom")
+	}
+	return rules
+}
+
+// typeCheckChangedOnly reports whether the type-check of a job reports only
+// errors on changed lines.
+func typeCheckChangedOnly(cfg *config.Config, job *Job) bool {
+	return !job.Full && !cfg.StaticAnalysisConfig.TypeScriptConfig.TypeCheckAllLines
+}
+
+// withoutPatches returns copies of the files without their diffs, so the
+// LLM reviews the whole files rather than only the changed lines.
+func withoutPatches(files []*models.File) []*models.File {
+	whole := make([]*models.File, len(files))
+	for i, file := range files {
+		copied := *file
+		copied.Patch = ""
+		whole[i] = &copied
+	}
+	return whole
+}
+
+// filterFiles drops the files excluded by the repository's path globs.
+func filterFiles(files []*models.File, settings *repoconfig.Settings) []*models.File {
+	var filtered []*models.File
+	for _, file := range files {
+		if len(settings.Include) > 0 && !glob.MatchAny(settings.Include, file.Path) {
+			continue
+		}
+		if glob.MatchAny(settings.Exclude, file.Path) {
+			continue
+		}
+		filtered = append(filtered, file)
+	}
+	return filtered
+}
+
+func (o *Orchestrator) gateFor(job *Job) gate.Config {
+	if o.gates == nil {
+		return gate.DefaultConfig(o.cfg.FailOnSeverity)
+	}
+	return o.gates.ForRepo(job.RepoOwner, job.RepoName)
+}
+
+// conclusion maps a review to a check run conclusion: a failed gate fails the
+// check, and passing reviews with issues are neutral.
+func conclusion(issues []*models.Issue, verdict *gate.Verdict) string {
+	switch {
+	case !verdict.Passed:
+		return github.ConclusionFailure
+	case len(issues) > 0:
+		return github.ConclusionNeutral
+	default:
+		return github.ConclusionSuccess
+	}
+}
+
+func verdictSummary(issues []*models.Issue, verdict *gate.Verdict) string {
+	if verdict.Passed {
+		if len(issues) == 0 {
+			return "Quality gate passed. No issues found."
+		}
+		return fmt.Sprintf("Quality gate passed with %d issues (highest severity: %s).",
+			len(issues), models.HighestSeverity(issues))
+	}
+	return "Quality gate failed:\n- " + strings.Join(verdict.Reasons, "\n- ")
+}
+
+// reportStatus publishes the gate verdict as a check run and a commit status.
+// The notes are appended to the check run summary.
+func (o *Orchestrator) reportStatus(ctx context.Context, job *Job, issues []*models.Issue, verdict *gate.Verdict, notes string) error {
+	if job.Provider != "github" || job.HeadSHA == "" {
+		return nil
+	}
+
+	title := fmt.Sprintf("%d issues found", len(issues))
+	summary := verdictSummary(issues, verdict) + notes
+	if err := o.githubClient.CreateCheckRun(ctx, job.RepoOwner, job.RepoName, job.HeadSHA,
+		checkRunName, conclusion(issues, verdict), title, summary); err != nil {
+		log.Printf("Warning: %v", err)
+	}
+
+	state, description := "success", "Quality gate passed"
+	if !verdict.Passed {
+		state = "failure"
+		description = "Quality gate failed: " + strings.Join(verdict.Reasons, "; ")
+	}
+	// The status API takes at most 140 characters.
+	if runes := []rune(description); len(runes) > 140 {
+		description = string(runes[:137]) + "..."
+	}
+	return o.githubClient.CreateCommitStatus(ctx, job.RepoOwner, job.RepoName, job.HeadSHA,
+		state, checkRunName, description)
+}
+
+func (o *Orchestrator) sendReviewComment(ctx context.Context, job *Job, issues []*models.Issue, comments []*models.ReviewComment, verdict *gate.Verdict, notes string) error {
 	if job.Provider != "github" {
 		return fmt.Errorf("unsupported provider: %s", job.Provider)
 	}
 
-	if len(comments) > 0 {
-		if err := o.githubClient.CreateReview(ctx, job.RepoOwner, job.RepoName, job.PRNumber, comments); err != nil {
+	event := github.ReviewEventComment
+	if !verdict.Passed {
+		event = github.ReviewEventRequestChanges
+	}
+
+	if len(comments) > 0 || !verdict.Passed {
+		summary := verdictSummary(issues, verdict) + notes
+		if err := o.githubClient.CreateReview(ctx, job.RepoOwner, job.RepoName, job.PRNumber, event, summary, comments); err != nil {
 			return fmt.Errorf("failed to create review: %w", err)
 		}
 	}
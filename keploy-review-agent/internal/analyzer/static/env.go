@@ -0,0 +1,36 @@
+package static
+
+import (
+	"os"
+	"strings"
+)
+
+// secretMarkers are the substrings of the names of environment variables
+// holding credentials, such as GITHUB_TOKEN or OPENAI_API_KEY.
+var secretMarkers = []string{"TOKEN", "KEY", "SECRET", "PASSWORD"}
+
+// scrubbedEnv returns the environment of the process without credentials,
+// followed by extra. Tools that load or run code of the reviewed repository
+// get this environment, so that the code cannot read the secrets of the
+// server.
+func scrubbedEnv(extra ...string) []string {
+	var env []string
+	for _, kv := range os.Environ() {
+		name, _, _ := strings.Cut(kv, "=")
+		if isSecret(name) {
+			continue
+		}
+		env = append(env, kv)
+	}
+	return append(env, extra...)
+}
+
+func isSecret(name string) bool {
+	name = strings.ToUpper(name)
+	for _, marker := range secretMarkers {
+		if strings.Contains(name, marker) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,350 @@
+package static
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"os"
+	"os/exec"
+	"path"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/keploy/keploy-review-agent/pkg/models"
+)
+
+// eslintExtensions are the files linted with ESLint.
+var eslintExtensions = []string{".ts", ".tsx", ".mts", ".cts", ".js", ".jsx", ".mjs", ".cjs"}
+
+// Configuration files in the order ESLint looks for them in a directory.
+// package.json counts as a legacy configuration when it has an eslintConfig
+// key.
+var (
+	eslintFlatConfigs   = []string{"eslint.config.js", "eslint.config.mjs", "eslint.config.cjs", "eslint.config.ts", "eslint.config.mts", "eslint.config.cts"}
+	eslintLegacyConfigs = []string{".eslintrc.js", ".eslintrc.cjs", ".eslintrc.yaml", ".eslintrc.yml", ".eslintrc.json", ".eslintrc"}
+)
+
+// eslintToolchainDependencies are installed once into the cache directory.
+// They lint with the built-in configuration, and with the configuration of
+// repositories that do not install ESLint themselves.
+var eslintToolchainDependencies = map[string]string{
+	"eslint":            "^9.0.0",
+	"typescript":        "^5.0.0",
+	"typescript-eslint": "^8.0.0",
+}
+
+// eslintBuiltinConfig is used for repositories without an ESLint
+// configuration.
+const eslintBuiltinConfig = `import tseslint from "typescript-eslint";
+
+export default [
+  {
+    files: ["**/*.js", "**/*.jsx", "**/*.mjs", "**/*.cjs"],
+    languageOptions: {
+      ecmaVersion: "latest",
+      sourceType: "module",
+      parserOptions: { ecmaFeatures: { jsx: true } },
+    },
+    rules: {
+      "no-unused-vars": "warn",
+      "no-console": "warn",
+    },
+  },
+  {
+    files: ["**/*.ts", "**/*.tsx", "**/*.mts", "**/*.cts"],
+    languageOptions: {
+      parser: tseslint.parser,
+      ecmaVersion: "latest",
+      sourceType: "module",
+    },
+    plugins: { "@typescript-eslint": tseslint.plugin },
+    rules: {
+      "@typescript-eslint/no-unused-vars": "warn",
+      "no-console": "warn",
+    },
+  },
+];
+`
+
+func isESLintFile(name string) bool {
+	ext := path.Ext(name)
+	for _, e := range eslintExtensions {
+		if ext == e {
+			return true
+		}
+	}
+	return false
+}
+
+// eslintToolchain is an ESLint installation shared by every review.
+type eslintToolchain struct {
+	dir string
+}
+
+func (t *eslintToolchain) binary() string {
+	return filepath.Join(t.dir, "node_modules", ".bin", "eslint")
+}
+
+// tsc type-checks projects that do not install TypeScript themselves.
+func (t *eslintToolchain) tsc() string {
+	return filepath.Join(t.dir, "node_modules", ".bin", "tsc")
+}
+
+func (t *eslintToolchain) config() string {
+	return filepath.Join(t.dir, "eslint.config.mjs")
+}
+
+// eslintToolchain returns the cached ESLint toolchain, installed the first
+// time it is needed and whenever its dependencies change.
+func (l *Linter) eslintToolchain() (*eslintToolchain, error) {
+	l.eslintOnce.Do(func() {
+		l.eslintTools = l.newESLintToolchain()
+		l.eslintErr = l.eslintTools.install()
+		if l.eslintErr != nil {
+			log.Printf("Warning: ESLint toolchain unavailable: %v", l.eslintErr)
+		}
+	})
+	return l.eslintTools, l.eslintErr
+}
+
+// version returns the installed version of ESLint, or "" when the toolchain
+// is not installed.
+func (t *eslintToolchain) version() string {
+	data, err := os.ReadFile(filepath.Join(t.dir, "node_modules", "eslint", "package.json"))
+	if err != nil {
+		return ""
+	}
+	var manifest struct {
+		Version string `json:"version"`
+	}
+	json.Unmarshal(data, &manifest)
+	return manifest.Version
+}
+
+func (l *Linter) newESLintToolchain() *eslintToolchain {
+	cacheDir := l.cfg.CacheDir
+	if cacheDir == "" {
+		cacheDir = os.TempDir()
+	}
+	return &eslintToolchain{dir: filepath.Join(cacheDir, "eslint")}
+}
+
+func (t *eslintToolchain) install() error {
+	manifest, err := json.MarshalIndent(map[string]interface{}{
+		"private":      true,
+		"type":         "module",
+		"dependencies": eslintToolchainDependencies,
+	}, "", "  ")
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(t.dir, 0755); err != nil {
+		return fmt.Errorf("failed to create %s: %w", t.dir, err)
+	}
+	if err := os.WriteFile(t.config(), []byte(eslintBuiltinConfig), 0644); err != nil {
+		return fmt.Errorf("failed to write the built-in ESLint config: %w", err)
+	}
+
+	manifestPath := filepath.Join(t.dir, "package.json")
+	if current, err := os.ReadFile(manifestPath); err == nil && bytes.Equal(current, manifest) {
+		if _, err := os.Stat(t.binary()); err == nil {
+			return nil
+		}
+	}
+	if err := os.WriteFile(manifestPath, manifest, 0644); err != nil {
+		return fmt.Errorf("failed to write %s: %w", manifestPath, err)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Minute)
+	defer cancel()
+	log.Printf("Installing the ESLint toolchain in %s", t.dir)
+	return runInstall(ctx, t.dir, npmInstall)
+}
+
+// eslintProject is a directory of a checkout whose files are linted with one
+// ESLint configuration.
+type eslintProject struct {
+	dir    string // where ESLint runs
+	config string // passed with --config; ESLint looks it up from dir when empty
+	legacy bool   // an eslintrc configuration
+}
+
+// builtin reports whether the project has no configuration of its own.
+func (p eslintProject) builtin() bool {
+	return p.dir == ""
+}
+
+// configuredESLint returns the project for the ESLint config of the
+// TypeScript settings, which is relative to the root of the repository
+// unless absolute.
+func (l *Linter) configuredESLint(root string) (eslintProject, bool) {
+	configured := l.cfg.StaticAnalysisConfig.TypeScriptConfig.ESLintConfig
+	if configured == "" {
+		return eslintProject{}, false
+	}
+	if !filepath.IsAbs(configured) {
+		configured = filepath.Join(root, filepath.FromSlash(configured))
+	}
+	return eslintProject{
+		dir:    root,
+		config: configured,
+		legacy: strings.HasPrefix(filepath.Base(configured), ".eslintrc"),
+	}, true
+}
+
+// eslintProjectFor returns the project linting the file name of the
+// checkout at root: the configured ESLint config or else the closest
+// configuration above the file. The zero project stands for the built-in
+// configuration.
+func (l *Linter) eslintProjectFor(root, name string) eslintProject {
+	if project, ok := l.configuredESLint(root); ok {
+		return project
+	}
+
+	dir := filepath.Dir(filepath.Join(root, filepath.FromSlash(name)))
+	for {
+		for _, config := range eslintFlatConfigs {
+			if fileExists(filepath.Join(dir, config)) {
+				return eslintProject{dir: dir}
+			}
+		}
+		for _, config := range eslintLegacyConfigs {
+			if fileExists(filepath.Join(dir, config)) {
+				return eslintProject{dir: dir, legacy: true}
+			}
+		}
+		if hasPackageESLintConfig(filepath.Join(dir, "package.json")) {
+			return eslintProject{dir: dir, legacy: true}
+		}
+		if dir == root || dir == filepath.Dir(dir) {
+			return eslintProject{}
+		}
+		dir = filepath.Dir(dir)
+	}
+}
+
+func hasPackageESLintConfig(name string) bool {
+	data, err := os.ReadFile(name)
+	if err != nil {
+		return false
+	}
+	var manifest struct {
+		ESLintConfig json.RawMessage `json:"eslintConfig"`
+	}
+	return json.Unmarshal(data, &manifest) == nil && len(manifest.ESLintConfig) > 0
+}
+
+// lintESLint lints the JavaScript and TypeScript files at paths in the
+// checkout at root with the ESLint configuration of the repository, and
+// returns the issues in those files. The dependencies of the repository are
+// installed so that its configuration finds its plugins and parsers; when
+// linting fails anyway, or the repository has no configuration, the files are linted
+// with the built-in configuration. ESLint configurations are JavaScript, so
+// the checkout of a fork is always linted with the built-in configuration.
+func (l *Linter) lintESLint(ctx context.Context, root string, fork bool, paths []string, files []*models.File) ([]*models.Issue, error) {
+	projects := make(map[eslintProject][]string)
+	projectPaths := make(map[eslintProject][]string)
+	for _, p := range paths {
+		var project eslintProject
+		if !fork {
+			project = l.eslintProjectFor(root, strings.TrimPrefix(p, "/"))
+		}
+		projects[project] = append(projects[project], filepath.Join(root, filepath.FromSlash(strings.TrimPrefix(p, "/"))))
+		projectPaths[project] = append(projectPaths[project], p)
+	}
+
+	var issues []*models.Issue
+	var failed, failedPaths []string
+	for project, projectFiles := range projects {
+		var output []byte
+		err := errors.New("no configuration")
+		if !project.builtin() {
+			binary := l.repositoryESLint(ctx, root, project.dir)
+			output, err = l.runESLint(ctx, binary, project, projectFiles)
+			if err != nil {
+				log.Printf("Warning: ESLint with the configuration in %s failed, using the built-in configuration: %v",
+					relativePath(root, project.dir), err)
+			}
+		}
+		if err != nil {
+			output, err = l.runBuiltinESLint(ctx, root, projectFiles)
+		}
+		if err != nil {
+			failed = append(failed, err.Error())
+			failedPaths = append(failedPaths, projectPaths[project]...)
+			continue
+		}
+		issues = append(issues, reviewedIssues(processLinterOutput(string(output), root), root, files)...)
+	}
+	if len(failed) > 0 {
+		sort.Strings(failed)
+		return issues, &PartialError{Paths: failedPaths, Err: fmt.Errorf("%s", strings.Join(failed, "; "))}
+	}
+	return issues, nil
+}
+
+// repositoryESLint returns the ESLint binary the dependencies of the
+// project in dir provide or, without one, the binary of the toolchain. It
+// returns "" when neither is available.
+func (l *Linter) repositoryESLint(ctx context.Context, root, dir string) string {
+	if binary := l.nodeBinary(ctx, root, dir, "eslint"); binary != "" {
+		return binary
+	}
+	tools, err := l.eslintToolchain()
+	if err != nil {
+		return ""
+	}
+	return tools.binary()
+}
+
+// runBuiltinESLint lints files in dir with the toolchain and its built-in
+// configuration.
+func (l *Linter) runBuiltinESLint(ctx context.Context, dir string, files []string) ([]byte, error) {
+	tools, err := l.eslintToolchain()
+	if err != nil {
+		return nil, err
+	}
+	return l.runESLint(ctx, tools.binary(), eslintProject{dir: dir, config: tools.config()}, files)
+}
+
+// runESLint runs ESLint for a project and returns its JSON report. ESLint
+// exits with status 1 when it finds problems.
+func (l *Linter) runESLint(ctx context.Context, binary string, project eslintProject, files []string) ([]byte, error) {
+	if binary == "" {
+		return nil, errors.New("ESLint is not installed")
+	}
+	args := []string{"--format", "json"}
+	if project.config != "" {
+		args = append(args, "--config", project.config)
+	}
+	if !project.legacy {
+		args = append(args, "--no-warn-ignored")
+	}
+	args = append(args, files...)
+
+	cmd := exec.CommandContext(ctx, binary, args...)
+	cmd.Dir = project.dir
+	cmd.Env = scrubbedEnv()
+	if project.legacy {
+		cmd.Env = append(cmd.Env, "ESLINT_USE_FLAT_CONFIG=false")
+	}
+	var stdout, stderr bytes.Buffer
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+	err := cmd.Run()
+	var exitErr *exec.ExitError
+	if err != nil && !(errors.As(err, &exitErr) && exitErr.ExitCode() == 1) {
+		return nil, fmt.Errorf("ESLint failed: %w: %s", err, strings.TrimSpace(stderr.String()))
+	}
+
+	var report []json.RawMessage
+	if err := json.Unmarshal(stdout.Bytes(), &report); err != nil {
+		return nil, fmt.Errorf("invalid ESLint JSON output: %w", err)
+	}
+	return stdout.Bytes(), nil
+}
@@ -0,0 +1,140 @@
+package static
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/keploy/keploy-review-agent/internal/analyzer/goanalysis"
+	"github.com/keploy/keploy-review-agent/internal/config"
+	"github.com/keploy/keploy-review-agent/pkg/models"
+)
+
+// stubModule is the go.mod written for Go files outside any module, such as
+// a snapshot of the changed files of a repository.
+const stubModule = "module keploy-review.local/snapshot\n\ngo 1.20\n"
+
+// lintGo analyzes the packages of the Go files at paths, which root holds at
+// their repository paths, with the configured engines, and returns the
+// issues in those files. In a checkout every Go module is analyzed on its
+// own; a snapshot of changed files is analyzed as one module without
+// dependencies.
+func (l *Linter) lintGo(ctx context.Context, root string, snapshot bool, paths []string, files []*models.File) ([]*models.Issue, error) {
+	goCfg := l.cfg.StaticAnalysisConfig.GoConfig
+	modules := map[string][]string{".": paths}
+	var env []string
+	if snapshot {
+		// The snapshot has no dependencies; never try to download them.
+		env = scrubbedEnv("GOFLAGS=-mod=mod", "GOPROXY=off", "GOWORK=off", "GOTOOLCHAIN=local")
+	} else {
+		// The go.mod of the checkout must not switch to another toolchain,
+		// which the go command would download and run, nor be rewritten.
+		env = scrubbedEnv("GOFLAGS=-mod=readonly", "GOWORK=off", "GOTOOLCHAIN=local")
+		modules = goModules(root, paths)
+	}
+
+	var issues []*models.Issue
+	var failed, failedPaths []string
+	for module, modulePaths := range modules {
+		moduleDir := filepath.Join(root, filepath.FromSlash(module))
+		if _, err := os.Stat(filepath.Join(moduleDir, "go.mod")); os.IsNotExist(err) {
+			if err := os.WriteFile(filepath.Join(moduleDir, "go.mod"), []byte(stubModule), 0644); err != nil {
+				return nil, fmt.Errorf("failed to write go.mod: %w", err)
+			}
+		}
+		patterns := goPackages(module, modulePaths)
+		moduleFailed := false
+
+		if goCfg.Engine != config.GoEngineGolangCI {
+			found, err := goanalysis.Run(ctx, moduleDir, env, patterns, goanalysis.Analyzers(goCfg.DisabledLinters))
+			if err != nil {
+				failed = append(failed, fmt.Sprintf("go/analysis in %s: %v", module, err))
+				moduleFailed = true
+			}
+			issues = append(issues, reviewedIssues(found, root, files)...)
+		}
+		if goCfg.Engine != config.GoEngineAnalysis {
+			found, err := l.runGolangCILint(ctx, root, moduleDir, env, patterns, files)
+			if err != nil {
+				failed = append(failed, fmt.Sprintf("golangci-lint in %s: %v", module, err))
+				moduleFailed = true
+			}
+			issues = append(issues, found...)
+		}
+		if moduleFailed {
+			failedPaths = append(failedPaths, modulePaths...)
+		}
+	}
+	if len(failed) > 0 {
+		sort.Strings(failed)
+		return issues, &PartialError{Paths: failedPaths, Err: fmt.Errorf("%s", strings.Join(failed, "; "))}
+	}
+	return issues, nil
+}
+
+// reviewedIssues keeps the issues in the reviewed files, with paths made
+// relative to root.
+func reviewedIssues(issues []*models.Issue, root string, files []*models.File) []*models.Issue {
+	reviewed := make(map[string]bool, len(files))
+	for _, file := range files {
+		reviewed[strings.TrimPrefix(file.Path, "/")] = true
+	}
+	var kept []*models.Issue
+	for _, issue := range issues {
+		issue.Path = relativePath(root, issue.Path)
+		if reviewed[issue.Path] {
+			kept = append(kept, issue)
+		}
+	}
+	return kept
+}
+
+// goPackages returns the patterns, relative to the directory of module, of
+// the packages holding paths.
+func goPackages(module string, paths []string) []string {
+	dirs := make(map[string]bool)
+	for _, p := range paths {
+		dir := path.Dir(strings.TrimPrefix(p, "/"))
+		if module != "." {
+			dir = strings.TrimPrefix(strings.TrimPrefix(dir, module), "/")
+		}
+		if dir == "" {
+			dir = "."
+		}
+		dirs[dir] = true
+	}
+	packages := make([]string, 0, len(dirs))
+	for dir := range dirs {
+		if dir == "." {
+			packages = append(packages, ".")
+		} else {
+			packages = append(packages, "./"+dir)
+		}
+	}
+	sort.Strings(packages)
+	return packages
+}
+
+// goModules groups paths by the repository-relative directory of the
+// closest go.mod above them, "." for the repository root.
+func goModules(root string, paths []string) map[string][]string {
+	modules := make(map[string][]string)
+	for _, p := range paths {
+		module := "."
+		for dir := path.Dir(strings.TrimPrefix(p, "/")); ; dir = path.Dir(dir) {
+			if _, err := os.Stat(filepath.Join(root, filepath.FromSlash(dir), "go.mod")); err == nil {
+				module = dir
+				break
+			}
+			if dir == "." || dir == "/" {
+				break
+			}
+		}
+		modules[module] = append(modules[module], p)
+	}
+	return modules
+}
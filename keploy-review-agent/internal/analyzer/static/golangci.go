@@ -0,0 +1,355 @@
+package static
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/keploy/keploy-review-agent/internal/config"
+	"github.com/keploy/keploy-review-agent/pkg/models"
+	"gopkg.in/yaml.v3"
+)
+
+// golangciDefaultLinters are enabled on top of golangci-lint's standard
+// linters when the configuration enables none.
+var golangciDefaultLinters = []string{"misspell", "gocritic"}
+
+// golangciStrictLinters are added in strict mode.
+var golangciStrictLinters = []string{"gosec", "revive", "errorlint", "bodyclose", "nilerr", "unparam"}
+
+var golangciVersionRegex = regexp.MustCompile(`version v?(\d+)\.`)
+
+// golangciTool is a golangci-lint binary. Versions 1 and 2 take different
+// configuration files and output flags.
+type golangciTool struct {
+	path    string
+	major   int
+	version string // output of golangci-lint version
+}
+
+// golangciLint returns the golangci-lint binary, looked up once per linter.
+func (l *Linter) golangciLint() (*golangciTool, error) {
+	l.golangciOnce.Do(func() {
+		l.golangci, l.golangciErr = findGolangCILint(l.cfg.StaticAnalysisConfig.GoConfig.GolangCILintPath)
+		if l.golangciErr != nil {
+			log.Printf("Warning: Go static analysis disabled: %v", l.golangciErr)
+			return
+		}
+		log.Printf("Using golangci-lint v%d at %s", l.golangci.major, l.golangci.path)
+	})
+	return l.golangci, l.golangciErr
+}
+
+// findGolangCILint resolves the configured golangci-lint binary or, without
+// one, looks for it on PATH and in the usual install locations.
+func findGolangCILint(configured string) (*golangciTool, error) {
+	binary := configured
+	if binary != "" {
+		if _, err := exec.LookPath(binary); err != nil {
+			return nil, fmt.Errorf("configured golangci-lint is not usable: %w", err)
+		}
+	} else if found, err := exec.LookPath("golangci-lint"); err == nil {
+		binary = found
+	} else {
+		for _, candidate := range golangciCandidates() {
+			if info, err := os.Stat(candidate); err == nil && !info.IsDir() {
+				binary = candidate
+				break
+			}
+		}
+		if binary == "" {
+			return nil, fmt.Errorf("golangci-lint not found on PATH")
+		}
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+	out, err := exec.CommandContext(ctx, binary, "version").CombinedOutput()
+	if err != nil {
+		return nil, fmt.Errorf("failed to run %s version: %w", binary, err)
+	}
+	m := golangciVersionRegex.FindSubmatch(out)
+	if m == nil {
+		return nil, fmt.Errorf("unrecognized golangci-lint version: %s", strings.TrimSpace(string(out)))
+	}
+	major, _ := strconv.Atoi(string(m[1]))
+	return &golangciTool{path: binary, major: major, version: strings.TrimSpace(string(out))}, nil
+}
+
+// golangciCandidates are the install locations checked when golangci-lint is
+// not on PATH, as with services started outside a login shell.
+func golangciCandidates() []string {
+	var candidates []string
+	if gobin := os.Getenv("GOBIN"); gobin != "" {
+		candidates = append(candidates, filepath.Join(gobin, "golangci-lint"))
+	}
+	gopath := os.Getenv("GOPATH")
+	if gopath == "" {
+		if home, err := os.UserHomeDir(); err == nil {
+			gopath = filepath.Join(home, "go")
+		}
+	}
+	if gopath != "" {
+		candidates = append(candidates, filepath.Join(filepath.SplitList(gopath)[0], "bin", "golangci-lint"))
+	}
+	return append(candidates, "/usr/local/bin/golangci-lint", "/snap/bin/golangci-lint")
+}
+
+// golangciConfig returns the golangci-lint configuration for the Go
+// settings. The configured linters are enabled on top of the standard ones;
+// strict mode adds stricter linters and turns off the default exclusions of
+// common false positives.
+func golangciConfig(goCfg config.GoConfig, major int) ([]byte, error) {
+	enabled := goCfg.EnabledLinters
+	if len(enabled) == 0 {
+		enabled = golangciDefaultLinters
+	}
+	if goCfg.StrictMode {
+		enabled = append(append([]string{}, enabled...), golangciStrictLinters...)
+	}
+	enabled = withoutLinters(enabled, goCfg.DisabledLinters)
+
+	linters := map[string]interface{}{
+		"enable":  enabled,
+		"disable": goCfg.DisabledLinters,
+	}
+	issues := map[string]interface{}{
+		"max-issues-per-linter": 0,
+		"max-same-issues":       0,
+	}
+	// Report paths relative to the directory golangci-lint runs in rather
+	// than to the temporary configuration file.
+	run := map[string]interface{}{"relative-path-mode": "wd"}
+	cfg := map[string]interface{}{"run": run, "linters": linters, "issues": issues}
+
+	if major >= 2 {
+		cfg["version"] = "2"
+		linters["default"] = "standard"
+		if !goCfg.StrictMode {
+			linters["exclusions"] = map[string]interface{}{
+				"presets": []string{"comments", "common-false-positives", "legacy", "std-error-handling"},
+			}
+		}
+	} else {
+		issues["exclude-use-default"] = !goCfg.StrictMode
+	}
+	return yaml.Marshal(cfg)
+}
+
+func withoutLinters(linters, excluded []string) []string {
+	skip := make(map[string]bool, len(excluded))
+	for _, linter := range excluded {
+		skip[linter] = true
+	}
+	var kept []string
+	seen := make(map[string]bool)
+	for _, linter := range linters {
+		if !skip[linter] && !seen[linter] {
+			seen[linter] = true
+			kept = append(kept, linter)
+		}
+	}
+	return kept
+}
+
+// runGolangCILint lints the packages matching patterns in the Go module at
+// moduleDir and returns the issues in the reviewed files, with paths
+// relative to root. A missing golangci-lint fails the analysis, so that the
+// review reports it as incomplete.
+func (l *Linter) runGolangCILint(ctx context.Context, root, moduleDir string, env, patterns []string, files []*models.File) ([]*models.Issue, error) {
+	tool, err := l.golangciLint()
+	if err != nil {
+		return nil, err
+	}
+
+	configData, err := golangciConfig(l.cfg.StaticAnalysisConfig.GoConfig, tool.major)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode golangci-lint config: %w", err)
+	}
+	configFile, err := os.CreateTemp("", "keploy-review-golangci-*.yml")
+	if err != nil {
+		return nil, fmt.Errorf("failed to write golangci-lint config: %w", err)
+	}
+	defer os.Remove(configFile.Name())
+	_, err = configFile.Write(configData)
+	if closeErr := configFile.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to write golangci-lint config: %w", err)
+	}
+
+	args := []string{"run", "--config", configFile.Name(), "--issues-exit-code=0"}
+	if tool.major >= 2 {
+		args = append(args, "--output.json.path=stdout")
+	} else {
+		args = append(args, "--out-format=json")
+	}
+	args = append(args, patterns...)
+
+	cmd := exec.CommandContext(ctx, tool.path, args...)
+	cmd.Dir = moduleDir
+	cmd.Env = env
+	var stdout, stderr bytes.Buffer
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+	if err := cmd.Run(); err != nil {
+		return nil, fmt.Errorf("%w: %s", err, strings.TrimSpace(stderr.String()))
+	}
+
+	return parseGolangCIOutput(stdout.Bytes(), moduleDir, root, files)
+}
+
+type golangciIssue struct {
+	FromLinter  string `json:"FromLinter"`
+	Text        string `json:"Text"`
+	Severity    string `json:"Severity"`
+	Replacement *struct {
+		NeedOnlyDelete bool     `json:"NeedOnlyDelete"`
+		NewLines       []string `json:"NewLines"`
+		Inline         *struct {
+			StartCol  int    `json:"StartCol"`
+			Length    int    `json:"Length"`
+			NewString string `json:"NewString"`
+		} `json:"Inline"`
+	} `json:"Replacement"`
+	LineRange *struct {
+		From int `json:"From"`
+		To   int `json:"To"`
+	} `json:"LineRange"`
+	Pos struct {
+		Filename string `json:"Filename"`
+		Line     int    `json:"Line"`
+		Column   int    `json:"Column"`
+	} `json:"Pos"`
+}
+
+// parseGolangCIOutput maps the JSON report of golangci-lint, run in wd, to
+// issues in the reviewed files, with paths relative to root. Type-checking
+// errors mostly come from dependencies that could not be loaded rather than
+// from the code, so they are dropped.
+func parseGolangCIOutput(output []byte, wd, root string, files []*models.File) ([]*models.Issue, error) {
+	// Version 2 prints a text summary after the JSON report.
+	if end := bytes.LastIndexByte(output, '}'); end >= 0 {
+		output = output[:end+1]
+	}
+	var report struct {
+		Issues []golangciIssue `json:"Issues"`
+	}
+	if err := json.Unmarshal(output, &report); err != nil {
+		return nil, fmt.Errorf("invalid golangci-lint output: %w", err)
+	}
+
+	contents := make(map[string]string, len(files))
+	for _, file := range files {
+		contents[strings.TrimPrefix(file.Path, "/")] = file.Content
+	}
+
+	var issues []*models.Issue
+	typecheck := 0
+	for _, gi := range report.Issues {
+		if gi.FromLinter == "typecheck" {
+			typecheck++
+			continue
+		}
+		filename := gi.Pos.Filename
+		if !filepath.IsAbs(filename) {
+			filename = filepath.Join(wd, filename)
+		}
+		filename = relativePath(root, filename)
+		content, ok := contents[filename]
+		if !ok {
+			continue
+		}
+
+		issue := &models.Issue{
+			Path:        filename,
+			Line:        gi.Pos.Line,
+			Column:      gi.Pos.Column,
+			EndLine:     gi.Pos.Line,
+			Severity:    golangciSeverity(gi.Severity),
+			RuleID:      gi.FromLinter,
+			Category:    "lint",
+			Title:       fmt.Sprintf("golangci-lint Issue: %s", gi.FromLinter),
+			Description: gi.Text,
+			Suggestion:  "Consider fixing this issue based on the linter's feedback.",
+			DocURL:      "https://golangci-lint.run/usage/linters/#" + gi.FromLinter,
+			Source:      "golangci-lint",
+		}
+		if gi.FromLinter == "gosec" {
+			issue.Category = "security"
+		}
+		if fix, ok := gi.fix(content); ok {
+			issue.Fixes = append(issue.Fixes, fix)
+		}
+		issues = append(issues, issue)
+	}
+	if typecheck > 0 {
+		log.Printf("golangci-lint: ignored %d type-checking errors", typecheck)
+	}
+	return issues, nil
+}
+
+// fix converts the replacement suggested by a linter into a fix. Inline
+// replacements use 0-based columns.
+func (gi *golangciIssue) fix(content string) (models.Fix, bool) {
+	r := gi.Replacement
+	if r == nil {
+		return models.Fix{}, false
+	}
+	from, to := gi.Pos.Line, gi.Pos.Line
+	if gi.LineRange != nil && gi.LineRange.From > 0 {
+		from, to = gi.LineRange.From, gi.LineRange.To
+	}
+	lines := strings.Split(content, "\n")
+	if from < 1 || to < from || to > len(lines) {
+		return models.Fix{}, false
+	}
+
+	description := fmt.Sprintf("Apply the %s fix", gi.FromLinter)
+	var edit models.TextEdit
+	switch {
+	case r.Inline != nil:
+		edit = models.TextEdit{
+			StartLine:   gi.Pos.Line,
+			StartColumn: r.Inline.StartCol + 1,
+			EndLine:     gi.Pos.Line,
+			EndColumn:   r.Inline.StartCol + r.Inline.Length + 1,
+			NewText:     r.Inline.NewString,
+		}
+	case r.NeedOnlyDelete:
+		if to == len(lines) {
+			return models.Fix{}, false
+		}
+		edit = models.TextEdit{StartLine: from, StartColumn: 1, EndLine: to + 1, EndColumn: 1}
+	default:
+		edit = models.TextEdit{
+			StartLine:   from,
+			StartColumn: 1,
+			EndLine:     to,
+			EndColumn:   len(lines[to-1]) + 1,
+			NewText:     strings.Join(r.NewLines, "\n"),
+		}
+	}
+	return models.Fix{Description: description, Edits: []models.TextEdit{edit}}, true
+}
+
+func golangciSeverity(severity string) models.Severity {
+	switch strings.ToLower(severity) {
+	case "error", "high":
+		return models.SeverityError
+	case "info", "low":
+		return models.SeverityInfo
+	default:
+		return models.SeverityWarning
+	}
+}
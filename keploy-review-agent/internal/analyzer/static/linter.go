@@ -128,18 +128,18 @@ package static
 
 import (
 
-	"bytes"
 	"context"
 	"encoding/json"
+	"errors"
 
 
 	"fmt"
 	"io/ioutil"
 	"log"
 	"os"
-	"os/exec"
 	"path/filepath"
 	"strings"
+	"sync"
 
 	"github.com/keploy/keploy-review-agent/internal/config"
 	"github.com/keploy/keploy-review-agent/pkg/models"
@@ -147,17 +147,89 @@ import (
 
 type Linter struct {
 	cfg *config.Config
+
+	golangciOnce sync.Once
+	golangci     *golangciTool
+	golangciErr  error
+
+	eslintOnce  sync.Once
+	eslintTools *eslintToolchain
+	eslintErr   error
+
+	installMu     sync.Mutex
+	installMarker string // names the file marking the dependencies installed by the linter
 }
 
 var Comment string
 
+// PartialError reports files whose linting failed, such as the files of a
+// Go module that did not load or of an ESLint project whose configuration
+// is broken. The issues found in the other files are still returned.
+type PartialError struct {
+	Paths []string
+	Err   error
+}
+
+func (e *PartialError) Error() string {
+	return e.Err.Error()
+}
+
+func (e *PartialError) Unwrap() error {
+	return e.Err
+}
+
+func (e *PartialError) FailedPaths() []string {
+	return e.Paths
+}
+
 func NewLinter(cfg *config.Config) *Linter {
 	return &Linter{
 		cfg: cfg,
 	}
 }
 
+// Analyze lints a snapshot of the files alone. Type-aware linters see only
+// the changed files, without their packages and dependencies; prefer
+// AnalyzeCheckout.
 func (l *Linter) Analyze(ctx context.Context, files []*models.File) ([]*models.Issue, error) {
+	return l.analyze(ctx, "", false, files)
+}
+
+// AnalyzeCheckout lints the files in a checkout of the repository at root,
+// so that Go linters see whole packages and modules and ESLint uses the
+// configuration of the repository, unless the checkout is the head of a
+// pull request from a fork. Issues are reported only for the given files,
+// with repository-relative paths.
+func (l *Linter) AnalyzeCheckout(ctx context.Context, root string, fork bool, files []*models.File) ([]*models.Issue, error) {
+	return l.analyze(ctx, root, fork, files)
+}
+
+// ToolVersions identifies the golangci-lint and ESLint installations, whose
+// upgrades change the results of the linter. Tools that are not installed yet
+// are not installed for it.
+func (l *Linter) ToolVersions() string {
+	var versions []string
+	if tool, err := l.golangciLint(); err == nil {
+		versions = append(versions, tool.path+" "+tool.version)
+	}
+	if v := l.newESLintToolchain().version(); v != "" {
+		versions = append(versions, "eslint "+v)
+	}
+	return strings.Join(versions, "; ")
+}
+
+// NeedsCheckout reports whether linting any of the files benefits from a
+// checkout of the repository.
+func NeedsCheckout(files []*models.File) bool {
+	for _, file := range files {
+		if strings.HasSuffix(file.Path, ".go") || isESLintFile(file.Path) {
+			return true
+		}
+	}
+	return false
+}
+
+func (l *Linter) analyze(ctx context.Context, root string, fork bool, files []*models.File) ([]*models.Issue, error) {
 	var issues []*models.Issue
 
 	tempDir, err := ioutil.TempDir("", "keploy-review-")
@@ -166,23 +238,35 @@ func (l *Linter) Analyze(ctx context.Context, files []*models.File) ([]*models.I
 	}
 	defer os.RemoveAll(tempDir)
 
-	var goFiles, tsFiles []string
+	var goFiles, tsFiles, tsPaths []string
 	hasGo, hasTS := false, false
 
 	for _, file := range files {
-		filePath := filepath.Join(tempDir, filepath.Base(file.Path))
+		// Files keep their repository layout, so that Go files are grouped
+		// into their packages.
+		filePath := filepath.Join(tempDir, filepath.FromSlash(strings.TrimPrefix(file.Path, "/")))
 
 		switch {
 		case strings.HasSuffix(file.Path, ".go"):
 			hasGo = true
-			goFiles = append(goFiles, filePath)
-		case strings.HasSuffix(file.Path, ".ts"):
+			goFiles = append(goFiles, file.Path)
+			if root != "" {
+				continue // linted in the checkout
+			}
+		case isESLintFile(file.Path):
 			hasTS = true
+			tsPaths = append(tsPaths, file.Path)
+			if root != "" {
+				continue
+			}
 			tsFiles = append(tsFiles, filePath)
 		default:
 			continue // Ignore non-Go/TS files
 		}
 
+		if err := os.MkdirAll(filepath.Dir(filePath), 0755); err != nil {
+			return nil, fmt.Errorf("failed to create directory for %s: %w", file.Path, err)
+		}
 		if err := ioutil.WriteFile(filePath, []byte(file.Content), 0644); err != nil {
 			return nil, fmt.Errorf("failed to write file %s: %w", file.Path, err)
 		}
@@ -202,32 +286,59 @@ func (l *Linter) Analyze(ctx context.Context, files []*models.File) ([]*models.I
 		Comment = "Add a TypeScript code file to your PR"
 	}
 
-	if len(tsFiles) > 0 {
-		linterOutput, err := l.RunESLint(ctx, tempDir, tsFiles)
-		if err != nil {
-			log.Fatalf("Error running ESLint: %v", err)
+	// A failed linter fails only the files it lints, or the part of them
+	// it names, so the results of the other files can still be cached.
+	var failed, failures []string
+	fail := func(linter string, paths []string, err error) {
+		var partial *PartialError
+		if errors.As(err, &partial) {
+			paths = partial.Paths
 		}
-
-		issues = append(issues, processLinterOutput(linterOutput)...)
+		failed = append(failed, paths...)
+		failures = append(failures, fmt.Sprintf("%s: %v", linter, err))
 	}
 
+	if len(tsPaths) > 0 && l.cfg.StaticAnalysisConfig.TypeScriptConfig.TypeScriptEnabled {
+		var tsIssues []*models.Issue
+		if root != "" {
+			tsIssues, err = l.lintESLint(ctx, root, fork, tsPaths, files)
+		} else {
+			var linterOutput string
+			linterOutput, err = l.RunESLint(ctx, tempDir, tsFiles)
+			tsIssues = processLinterOutput(linterOutput, tempDir)
+		}
+		if err != nil {
+			fail("ESLint", tsPaths, err)
+		}
+		issues = append(issues, tsIssues...)
+	}
 
-
-
-
-
-
-
+	if len(goFiles) > 0 {
+		goRoot := root
+		if goRoot == "" {
+			goRoot = tempDir
+		}
+		goIssues, err := l.lintGo(ctx, goRoot, root == "", goFiles, files)
+		if err != nil {
+			fail("Go static analysis", goFiles, err)
+		}
+		issues = append(issues, goIssues...)
+	}
 
 	fmt.Printf("Total Issues Found: %d\n", len(issues))
 	for i, issue := range issues {
 		fmt.Printf("Issue %d: %+v\n", i+1, issue)
 	}
 
+	if len(failures) > 0 {
+		return issues, &PartialError{Paths: failed, Err: errors.New(strings.Join(failures, "; "))}
+	}
 	return issues, nil
 }
 
-func processLinterOutput(output string) []*models.Issue {
+// processLinterOutput maps the JSON report of ESLint, run on the files in
+// dir, to issues with repository-relative paths.
+func processLinterOutput(output, dir string) []*models.Issue {
 	var issues []*models.Issue
 
 	output = strings.TrimSpace(output)
@@ -238,12 +349,20 @@ func processLinterOutput(output string) []*models.Issue {
 
 	var lintResults []struct {
 		FilePath string `json:"filePath"`
+		Source   string `json:"source"`
 		Messages []struct {
-			RuleID   string `json:"ruleId"`
-			Severity int    `json:"severity"`
-			Message  string `json:"message"`
-			Line     int    `json:"line"`
-			Column   int    `json:"column"`
+			RuleID      string      `json:"ruleId"`
+			Severity    int         `json:"severity"`
+			Message     string      `json:"message"`
+			Line        int         `json:"line"`
+			Column      int         `json:"column"`
+			EndLine     int         `json:"endLine"`
+			EndColumn   int         `json:"endColumn"`
+			Fix         *eslintFix  `json:"fix"`
+			Suggestions []struct {
+				Desc string     `json:"desc"`
+				Fix  *eslintFix `json:"fix"`
+			} `json:"suggestions"`
 		} `json:"messages"`
 	}
 
@@ -254,21 +373,35 @@ func processLinterOutput(output string) []*models.Issue {
 
 	for _, result := range lintResults {
 		for _, msg := range result.Messages {
-			if msg.Message == "File ignored because no matching configuration was supplied." {
-
+			// Files the configuration ignores are reported as warnings
+			// without a rule.
+			if msg.RuleID == "" && strings.HasPrefix(msg.Message, "File ignored ") {
 				continue
 			}
 
 			issue := &models.Issue{
-				Path:        result.FilePath,
+				Path:        relativePath(dir, result.FilePath),
 				Line:        msg.Line,
 				Column:      msg.Column,
-				Severity:    models.Severity(severityToString(msg.Severity)),
+				EndLine:     msg.EndLine,
+				EndColumn:   msg.EndColumn,
+				Severity:    eslintSeverity(msg.Severity),
+				RuleID:      msg.RuleID,
+				Category:    "lint",
 				Title:       fmt.Sprintf("ESLint Issue: %s", msg.RuleID),
 				Description: msg.Message,
 				Suggestion:  "Consider fixing this issue based on the linter's feedback.",
+				DocURL:      eslintRuleURL(msg.RuleID),
 				Source:      "ESLint",
 			}
+			if msg.Fix != nil {
+				issue.Fixes = append(issue.Fixes, msg.Fix.toFix(result.Source, "Apply the ESLint autofix"))
+			}
+			for _, suggestion := range msg.Suggestions {
+				if suggestion.Fix != nil {
+					issue.Fixes = append(issue.Fixes, suggestion.Fix.toFix(result.Source, suggestion.Desc))
+				}
+			}
 			issues = append(issues, issue)
 		}
 	}
@@ -276,127 +409,93 @@ func processLinterOutput(output string) []*models.Issue {
 	return issues
 }
 
-func severityToString(severity int) string {
-	switch severity {
-	case 1:
-		return "warning"
-	case 2:
-		return "error"
-	default:
-		return "info"
+// relativePath returns the path of a file reported by a tool relative to the
+// directory the tool ran in, or the path unchanged when it lies elsewhere.
+func relativePath(dir, file string) string {
+	if !filepath.IsAbs(file) {
+		return filepath.ToSlash(file)
+	}
+	if resolved, err := filepath.EvalSymlinks(dir); err == nil && !strings.HasPrefix(file, dir) {
+		dir = resolved
 	}
+	rel, err := filepath.Rel(dir, file)
+	if err != nil || strings.HasPrefix(rel, "..") {
+		return file
+	}
+	return filepath.ToSlash(rel)
 }
 
+type eslintFix struct {
+	Range [2]int `json:"range"`
+	Text  string `json:"text"`
+}
 
-func (l *Linter) runGolangCILint(ctx context.Context, dir string, files []string) (string, error) {
-
-	if _, err := exec.LookPath("/snap/bin/golangci-lint"); err != nil {
-		fmt.Println("golangci-lint not found:", err)
-		return "", nil // Don't stop execution
+// toFix converts ESLint's character offsets into line/column positions using
+// the file source ESLint reports alongside the messages.
+func (f *eslintFix) toFix(source, description string) models.Fix {
+	startLine, startColumn := offsetToPosition(source, f.Range[0])
+	endLine, endColumn := offsetToPosition(source, f.Range[1])
+	return models.Fix{
+		Description: description,
+		Edits: []models.TextEdit{{
+			StartLine:   startLine,
+			StartColumn: startColumn,
+			EndLine:     endLine,
+			EndColumn:   endColumn,
+			NewText:     f.Text,
+		}},
 	}
+}
 
-	configPath := filepath.Join(dir, ".golangci.yml")
-	configContent := `
-version: 2 
-linters:
-  enable:
-    - govet
-    - staticcheck
-    - errcheck
-    - ineffassign
-    - unused
-    - misspell
-    - gocritic
-`
-	if err := os.WriteFile(configPath, []byte(configContent), 0644); err != nil {
-		fmt.Println("Failed to write golangci-lint config:", err)
-		return "", nil // Don't stop execution
+func offsetToPosition(source string, offset int) (int, int) {
+	if offset > len(source) {
+		offset = len(source)
 	}
+	prefix := source[:offset]
+	line := strings.Count(prefix, "\n") + 1
+	column := offset - strings.LastIndex(prefix, "\n")
+	return line, column
+}
 
-	cmdArgs := []string{
-		"/snap/bin/golangci-lint", "run",
-		"--config", configPath,
-		"--output.json.path", "stdout",
+// eslintRuleURL links core ESLint rules to their documentation. Plugin rules
+// ("plugin/rule") have no common documentation location.
+func eslintRuleURL(ruleID string) string {
+	if ruleID == "" || strings.Contains(ruleID, "/") {
+		return ""
 	}
-	cmdArgs = append(cmdArgs, files...)
-
-	cmd := exec.CommandContext(ctx, cmdArgs[0], cmdArgs[1:]...)
-	output, err := cmd.CombinedOutput()
+	return "https://eslint.org/docs/latest/rules/" + ruleID
+}
 
-	if err != nil {
-		fmt.Println("golangci-lint encountered an error:", err)
+func eslintSeverity(severity int) models.Severity {
+	switch severity {
+	case 1:
+		return models.SeverityWarning
+	case 2:
+		return models.SeverityError
+	default:
+		return models.SeverityInfo
 	}
-
-	return string(output), nil
 }
 
+
+// RunESLint lints files written to dir without the rest of their repository
+// and returns the JSON report. Only an absolute ESLint config can be used
+// without a checkout; otherwise the files are linted with the built-in
+// configuration.
 func (l *Linter) RunESLint(ctx context.Context, dir string, files []string) (string, error) {
 	if len(files) == 0 {
 		return "", fmt.Errorf("no files provided for ESLint")
 	}
 
-	configPath := filepath.Join(dir, "eslint.config.js")
-	if _, err := os.Stat(configPath); os.IsNotExist(err) {
-		configContent :=
-		`
-		export default [
-  {
-    ignores: [],
-    files: ["**/*.ts", "**/*.tsx", "**/*.js", "**/*.jsx"],
-    languageOptions: {
-      parserOptions: {
-        ecmaVersion: "latest",
-        sourceType: "module",
-      },
-    },
-    rules: {
-      "no-unused-vars": "warn",
-      "no-console": "warn",
-    },
-  },
-];
-`
-		if err := os.WriteFile(configPath, []byte(configContent), 0644); err != nil {
-			return "", fmt.Errorf("failed to create ESLint config file: %w", err)
-		}
-	}
-
-	packagePath := filepath.Join(dir, "package.json")
-	if _, err := os.Stat(packagePath); os.IsNotExist(err) {
-		packageContent := "{\"type\": \"module\"}"
-		if err := os.WriteFile(packagePath, []byte(packageContent), 0644); err != nil {
-			return "", fmt.Errorf("failed to create package.json: %w", err)
-		}
-	}
-
-	cmdCheck := exec.CommandContext(ctx, "npm", "list", "@eslint/js")
-	cmdCheck.Dir = dir
-	if err := cmdCheck.Run(); err != nil {
-
-		cmdInstall := exec.CommandContext(ctx, "npm", "install", "--save-dev", "@eslint/js")
-		cmdInstall.Dir = dir
-		if err := cmdInstall.Run(); err != nil {
-			return "", fmt.Errorf("failed to install ESLint dependencies: %w", err)
-		}
-	}
-
-	args := append([]string{"--format", "json", "--config", configPath}, files...)
-	cmd := exec.CommandContext(ctx, "npx", append([]string{"eslint"}, args...)...)
-	cmd.Dir = dir
-
-	var out bytes.Buffer
-	cmd.Stdout = &out
-	cmd.Stderr = &out
-
-	if err := cmd.Run(); err != nil {
-		fmt.Printf("ESLint encountered issues but continuing: %s\n", err)
+	project, ok := l.configuredESLint(dir)
+	if !ok || !filepath.IsAbs(l.cfg.StaticAnalysisConfig.TypeScriptConfig.ESLintConfig) {
+		output, err := l.runBuiltinESLint(ctx, dir, files)
+		return string(output), err
 	}
-
-	var lintResults []map[string]interface{}
-	if jsonErr := json.Unmarshal(out.Bytes(), &lintResults); jsonErr != nil {
-		return "", fmt.Errorf("invalid ESLint JSON output: %w\nOutput: %s", jsonErr, out.String())
+	tools, err := l.eslintToolchain()
+	if err != nil {
+		return "", err
 	}
-
-	fmt.Printf("ESLint output: %s\n", out.String())
-	return out.String(), nil
-}
\ No newline at end of file
+	output, err := l.runESLint(ctx, tools.binary(), project, files)
+	return string(output), err
+}
@@ -0,0 +1,166 @@
+package static
+
+import (
+	"bytes"
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"io/fs"
+	"log"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+)
+
+// Installing JavaScript dependencies never runs their install scripts.
+var lockfileInstalls = []struct {
+	lockfile string
+	command  []string
+}{
+	{"pnpm-lock.yaml", []string{"pnpm", "install", "--frozen-lockfile", "--ignore-scripts"}},
+	{"yarn.lock", []string{"yarn", "install", "--frozen-lockfile", "--ignore-scripts"}},
+	{"package-lock.json", []string{"npm", "ci", "--ignore-scripts", "--no-audit", "--no-fund"}},
+	{"npm-shrinkwrap.json", []string{"npm", "ci", "--ignore-scripts", "--no-audit", "--no-fund"}},
+}
+
+var npmInstall = []string{"npm", "install", "--ignore-scripts", "--no-audit", "--no-fund", "--no-package-lock"}
+
+// nodeBinary returns the binary name that the dependencies of the project
+// in dir provide, installing them first. Only dependencies installed by the
+// linter are trusted, never binaries committed to the repository. It returns
+// "" when the dependencies do not provide name.
+func (l *Linter) nodeBinary(ctx context.Context, root, dir, name string) string {
+	packageDir := l.installDependencies(ctx, root, dir)
+	if packageDir == "" {
+		return ""
+	}
+	return findInParents(packageDir, dir, filepath.Join("node_modules", ".bin", name))
+}
+
+// installDependencies installs the dependencies of the closest package above
+// dir in the checkout at root, unless the linter already did, and returns
+// the directory of the package, or "" when there is none or the install
+// failed. node_modules directories of the repository are removed first.
+// Installs are serialized, as analyzers of one review share the checkout.
+func (l *Linter) installDependencies(ctx context.Context, root, dir string) string {
+	packageDir := packageRoot(root, dir)
+	if packageDir == "" {
+		return ""
+	}
+
+	l.installMu.Lock()
+	defer l.installMu.Unlock()
+	if l.installMarker == "" {
+		l.installMarker = newInstallMarker()
+	}
+	marker := filepath.Join(packageDir, "node_modules", l.installMarker)
+	if fileExists(marker) {
+		return packageDir
+	}
+	if err := removeNodeModules(packageDir); err != nil {
+		log.Printf("Warning: Failed to remove the node_modules of the repository: %v", err)
+		return ""
+	}
+
+	command := npmInstall
+	for _, install := range lockfileInstalls {
+		if fileExists(filepath.Join(packageDir, install.lockfile)) {
+			command = install.command
+			break
+		}
+	}
+	log.Printf("Installing the dependencies of %s with %s", relativePath(root, packageDir), command[0])
+	if err := runInstall(ctx, packageDir, command); err != nil {
+		log.Printf("Warning: Failed to install JavaScript dependencies: %v", err)
+		return ""
+	}
+	if err := os.MkdirAll(filepath.Dir(marker), 0755); err != nil {
+		log.Printf("Warning: Failed to mark JavaScript dependencies as installed: %v", err)
+		return ""
+	}
+	if err := os.WriteFile(marker, nil, 0644); err != nil {
+		log.Printf("Warning: Failed to mark JavaScript dependencies as installed: %v", err)
+		return ""
+	}
+	return packageDir
+}
+
+// newInstallMarker returns the name of the file marking the dependencies
+// installed by a linter. The name is random, so that a repository cannot
+// commit a marker along with its own binaries.
+func newInstallMarker() string {
+	b := make([]byte, 16)
+	if _, err := rand.Read(b); err != nil {
+		panic(err)
+	}
+	return ".keploy-review-" + hex.EncodeToString(b)
+}
+
+// removeNodeModules removes the node_modules directories under dir.
+func removeNodeModules(dir string) error {
+	return filepath.WalkDir(dir, func(path string, entry fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if entry.Name() != "node_modules" {
+			return nil
+		}
+		if err := os.RemoveAll(path); err != nil {
+			return err
+		}
+		if entry.IsDir() {
+			return filepath.SkipDir
+		}
+		return nil
+	})
+}
+
+func runInstall(ctx context.Context, dir string, command []string) error {
+	cmd := exec.CommandContext(ctx, command[0], command[1:]...)
+	cmd.Dir = dir
+	// .npmrc files expand environment variables, which would send the
+	// credentials of the server to the registry of the repository.
+	cmd.Env = scrubbedEnv()
+	var stderr bytes.Buffer
+	cmd.Stderr = &stderr
+	if err := cmd.Run(); err != nil {
+		return fmt.Errorf("%s failed: %w: %s", strings.Join(command[:2], " "), err, strings.TrimSpace(stderr.String()))
+	}
+	return nil
+}
+
+// packageRoot returns the directory whose dependencies the project in dir
+// uses: the closest one with a lockfile, as at the root of a workspace, or
+// else the closest one with a package.json.
+func packageRoot(root, dir string) string {
+	for _, install := range lockfileInstalls {
+		if lockfile := findInParents(root, dir, install.lockfile); lockfile != "" {
+			return filepath.Dir(lockfile)
+		}
+	}
+	if manifest := findInParents(root, dir, "package.json"); manifest != "" {
+		return filepath.Dir(manifest)
+	}
+	return ""
+}
+
+// findInParents returns the first existing file name in dir or one of its
+// parents up to root.
+func findInParents(root, dir, name string) string {
+	for {
+		if candidate := filepath.Join(dir, name); fileExists(candidate) {
+			return candidate
+		}
+		if dir == root || dir == filepath.Dir(dir) {
+			return ""
+		}
+		dir = filepath.Dir(dir)
+	}
+}
+
+func fileExists(name string) bool {
+	info, err := os.Stat(name)
+	return err == nil && !info.IsDir()
+}
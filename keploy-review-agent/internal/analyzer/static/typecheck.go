@@ -0,0 +1,237 @@
+package static
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"os"
+	"os/exec"
+	"path"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/keploy/keploy-review-agent/internal/diff"
+	"github.com/keploy/keploy-review-agent/pkg/models"
+)
+
+// typeScriptExtensions are the files type-checked with tsc.
+var typeScriptExtensions = []string{".ts", ".tsx", ".mts", ".cts"}
+
+// tscDiagnosticRegex matches the first line of a diagnostic of tsc without
+// --pretty, such as "src/a.ts(3,7): error TS2322: Type 'string' is not
+// assignable to type 'number'.". Longer messages continue on indented lines.
+var tscDiagnosticRegex = regexp.MustCompile(`^(.+)\((\d+),(\d+)\): (error|warning|message) (TS\d+): (.*)$`)
+
+var tsCodeRegex = regexp.MustCompile(`^TS\d+$`)
+
+// IsTypeScriptCode reports whether rule is a TypeScript diagnostic code, the
+// rule ID of type-check issues.
+func IsTypeScriptCode(rule string) bool {
+	return tsCodeRegex.MatchString(rule)
+}
+
+// HasTypeScript reports whether any file is TypeScript source.
+func HasTypeScript(files []*models.File) bool {
+	for _, file := range files {
+		if isTypeScriptFile(file.Path) {
+			return true
+		}
+	}
+	return false
+}
+
+func isTypeScriptFile(name string) bool {
+	ext := path.Ext(name)
+	for _, e := range typeScriptExtensions {
+		if ext == e {
+			return true
+		}
+	}
+	return false
+}
+
+// TypeCheck type-checks the TypeScript projects holding the files in the
+// checkout at root, each with its own tsconfig.json, and returns the errors
+// in those files. With changedOnly, only errors on lines added by the diff
+// of a file are reported; files without a diff are reported in full. The
+// checkout of a fork is type-checked with the tsc of the toolchain, never
+// with its own scripts or binaries.
+func (l *Linter) TypeCheck(ctx context.Context, root string, fork bool, files []*models.File, changedOnly bool) ([]*models.Issue, error) {
+	projects := make(map[string]bool)
+	for _, file := range files {
+		if !isTypeScriptFile(file.Path) {
+			continue
+		}
+		dir := filepath.Dir(filepath.Join(root, filepath.FromSlash(strings.TrimPrefix(file.Path, "/"))))
+		if tsconfig := findInParents(root, dir, "tsconfig.json"); tsconfig != "" {
+			projects[filepath.Dir(tsconfig)] = true
+		}
+	}
+
+	var issues []*models.Issue
+	var failed []string
+	for dir := range projects {
+		output, err := l.runTypeCheck(ctx, root, dir, fork)
+		if err != nil {
+			failed = append(failed, fmt.Sprintf("%s: %v", relativePath(root, dir), err))
+			continue
+		}
+		issues = append(issues, reviewedIssues(parseTSCOutput(output, dir), root, files)...)
+	}
+	if changedOnly {
+		issues = onChangedLines(issues, files)
+	}
+	if len(failed) > 0 {
+		sort.Strings(failed)
+		return issues, fmt.Errorf("type-check failed in %s", strings.Join(failed, "; "))
+	}
+	return issues, nil
+}
+
+// runTypeCheck type-checks the project in dir with tsc --noEmit or, when
+// enabled, with its typecheck script, and returns the diagnostics. Both exit
+// with an error status when they find type errors. A fork is always checked
+// with the tsc of the toolchain; its dependencies are still installed,
+// without their scripts, for their types.
+func (l *Linter) runTypeCheck(ctx context.Context, root, dir string, fork bool) ([]byte, error) {
+	var cmd *exec.Cmd
+	useScript := l.cfg.StaticAnalysisConfig.TypeScriptConfig.TypeCheckScript && !fork
+	if useScript && hasTypeCheckScript(filepath.Join(dir, "package.json")) {
+		l.installDependencies(ctx, root, dir)
+		cmd = exec.CommandContext(ctx, "npm", "run", "--silent", "typecheck")
+	} else {
+		var tsc string
+		if fork {
+			l.installDependencies(ctx, root, dir)
+		} else {
+			tsc = l.nodeBinary(ctx, root, dir, "tsc")
+		}
+		if tsc == "" {
+			tools, err := l.eslintToolchain()
+			if err != nil {
+				return nil, fmt.Errorf("tsc is not installed: %w", err)
+			}
+			tsc = tools.tsc()
+		}
+		cmd = exec.CommandContext(ctx, tsc, "--noEmit", "--pretty", "false", "--project", "tsconfig.json")
+	}
+	cmd.Dir = dir
+	cmd.Env = scrubbedEnv()
+	var stdout, stderr bytes.Buffer
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+	err := cmd.Run()
+
+	var exitErr *exec.ExitError
+	if err != nil && !(errors.As(err, &exitErr) && tscDiagnosticRegex.Match(firstDiagnostic(stdout.Bytes()))) {
+		output := strings.TrimSpace(stderr.String() + "\n" + stdout.String())
+		if len(output) > 2000 {
+			output = output[len(output)-2000:]
+		}
+		return nil, fmt.Errorf("%w: %s", err, output)
+	}
+	return stdout.Bytes(), nil
+}
+
+func hasTypeCheckScript(name string) bool {
+	data, err := os.ReadFile(name)
+	if err != nil {
+		return false
+	}
+	var manifest struct {
+		Scripts map[string]string `json:"scripts"`
+	}
+	return json.Unmarshal(data, &manifest) == nil && manifest.Scripts["typecheck"] != ""
+}
+
+// firstDiagnostic returns the first line of output that looks like a tsc
+// diagnostic, or nil.
+func firstDiagnostic(output []byte) []byte {
+	for _, line := range bytes.Split(output, []byte("\n")) {
+		if line = bytes.TrimRight(line, "\r"); tscDiagnosticRegex.Match(line) {
+			return line
+		}
+	}
+	return nil
+}
+
+// parseTSCOutput maps the diagnostics of tsc, run in dir, to issues with
+// absolute paths.
+func parseTSCOutput(output []byte, dir string) []*models.Issue {
+	var issues []*models.Issue
+	var last *models.Issue
+	scanner := bufio.NewScanner(bytes.NewReader(output))
+	scanner.Buffer(make([]byte, 64*1024), 1024*1024)
+	for scanner.Scan() {
+		text := strings.TrimRight(scanner.Text(), "\r")
+		m := tscDiagnosticRegex.FindStringSubmatch(text)
+		if m == nil {
+			// Elaborations of the previous message are indented.
+			if last != nil && strings.HasPrefix(text, " ") {
+				last.Description += "\n" + strings.TrimSpace(text)
+			} else {
+				last = nil
+			}
+			continue
+		}
+
+		filename := m[1]
+		if !filepath.IsAbs(filename) {
+			filename = filepath.Join(dir, filepath.FromSlash(filename))
+		}
+		line, _ := strconv.Atoi(m[2])
+		column, _ := strconv.Atoi(m[3])
+		severity := models.SeverityError
+		if m[4] != "error" {
+			severity = models.SeverityWarning
+		}
+		last = &models.Issue{
+			Path:        filename,
+			Line:        line,
+			Column:      column,
+			EndLine:     line,
+			Severity:    severity,
+			RuleID:      m[5],
+			Category:    "bug",
+			Title:       fmt.Sprintf("TypeScript Error: %s", m[5]),
+			Description: m[6],
+			Suggestion:  "Fix the types so that the project compiles.",
+			Source:      "tsc",
+		}
+		issues = append(issues, last)
+	}
+	return issues
+}
+
+// onChangedLines keeps the issues on lines added by the diffs of their
+// files. Files without a diff, or with one that cannot be parsed, keep all
+// their issues.
+func onChangedLines(issues []*models.Issue, files []*models.File) []*models.Issue {
+	added := make(map[string]map[int]bool)
+	for _, file := range files {
+		if file.Patch == "" {
+			continue
+		}
+		hunks, err := diff.Parse(file.Patch)
+		if err != nil {
+			log.Printf("Warning: Failed to parse the diff of %s, reporting all type errors: %v", file.Path, err)
+			continue
+		}
+		added[strings.TrimPrefix(file.Path, "/")] = diff.AddedLines(hunks)
+	}
+
+	var kept []*models.Issue
+	for _, issue := range issues {
+		if lines, ok := added[issue.Path]; !ok || lines[issue.Line] {
+			kept = append(kept, issue)
+		}
+	}
+	return kept
+}
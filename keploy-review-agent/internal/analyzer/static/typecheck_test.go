@@ -0,0 +1,132 @@
+package static
+
+import (
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"github.com/keploy/keploy-review-agent/pkg/models"
+)
+
+func TestParseTSCOutput(t *testing.T) {
+	dir := filepath.FromSlash("/repo/web")
+	type result struct {
+		Path        string
+		Line        int
+		Column      int
+		Severity    models.Severity
+		Rule        string
+		Description string
+	}
+
+	tests := []struct {
+		name   string
+		output string
+		want   []result
+	}{
+		{
+			name:   "no diagnostics",
+			output: "",
+		},
+		{
+			name:   "relative path",
+			output: "src/a.ts(3,7): error TS2322: Type 'string' is not assignable to type 'number'.\n",
+			want: []result{{
+				Path: filepath.Join(dir, "src", "a.ts"), Line: 3, Column: 7, Severity: models.SeverityError,
+				Rule: "TS2322", Description: "Type 'string' is not assignable to type 'number'.",
+			}},
+		},
+		{
+			name: "elaborations continue the message",
+			output: "src/b.ts(10,1): error TS2345: Argument of type '{}' is not assignable.\r\n" +
+				"  Property 'id' is missing in type '{}'.\r\n" +
+				"src/c.tsx(1,20): warning TS6133: 'x' is declared but its value is never read.\n",
+			want: []result{
+				{
+					Path: filepath.Join(dir, "src", "b.ts"), Line: 10, Column: 1, Severity: models.SeverityError,
+					Rule: "TS2345", Description: "Argument of type '{}' is not assignable.\nProperty 'id' is missing in type '{}'.",
+				},
+				{
+					Path: filepath.Join(dir, "src", "c.tsx"), Line: 1, Column: 20, Severity: models.SeverityWarning,
+					Rule: "TS6133", Description: "'x' is declared but its value is never read.",
+				},
+			},
+		},
+		{
+			name: "other output ends an elaboration",
+			output: "> web@1.0.0 typecheck\n" +
+				"  indented but not a continuation\n" +
+				"a.ts(2,3): error TS1005: ';' expected.\n" +
+				"Found 1 error.\n" +
+				"  not a continuation either\n",
+			want: []result{{
+				Path: filepath.Join(dir, "a.ts"), Line: 2, Column: 3, Severity: models.SeverityError,
+				Rule: "TS1005", Description: "';' expected.",
+			}},
+		},
+		{
+			name:   "absolute path",
+			output: filepath.FromSlash("/repo/lib/d.ts") + "(5,9): error TS2304: Cannot find name 'foo'.\n",
+			want: []result{{
+				Path: filepath.FromSlash("/repo/lib/d.ts"), Line: 5, Column: 9, Severity: models.SeverityError,
+				Rule: "TS2304", Description: "Cannot find name 'foo'.",
+			}},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var got []result
+			for _, issue := range parseTSCOutput([]byte(tt.output), dir) {
+				got = append(got, result{issue.Path, issue.Line, issue.Column, issue.Severity, issue.RuleID, issue.Description})
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("parseTSCOutput() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestFirstDiagnostic(t *testing.T) {
+	tests := []struct {
+		name   string
+		output string
+		want   string
+	}{
+		{"no output", "", ""},
+		{"no diagnostic", "error: could not find tsconfig.json\n", ""},
+		{
+			name:   "after other output",
+			output: "> tsc --noEmit\r\n\r\nsrc/a.ts(1,1): error TS2304: Cannot find name 'x'.\r\nsrc/b.ts(2,2): error TS2304: Cannot find name 'y'.\r\n",
+			want:   "src/a.ts(1,1): error TS2304: Cannot find name 'x'.",
+		},
+	}
+	for _, tt := range tests {
+		if got := string(firstDiagnostic([]byte(tt.output))); got != tt.want {
+			t.Errorf("%s: firstDiagnostic() = %q, want %q", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestOnChangedLines(t *testing.T) {
+	files := []*models.File{
+		{Path: "src/a.ts", Patch: "@@ -1,2 +1,3 @@\n const a = 1\n+const b: number = \"x\"\n const c = 2"},
+		{Path: "/src/b.ts"},
+		{Path: "src/c.ts", Patch: "@@ -1 +1 @@\n?garbled"},
+	}
+	issue := func(path string, line int) *models.Issue {
+		return &models.Issue{Path: path, Line: line}
+	}
+	issues := []*models.Issue{
+		issue("src/a.ts", 1),
+		issue("src/a.ts", 2),
+		issue("src/b.ts", 7),
+		issue("src/c.ts", 4),
+		issue("src/other.ts", 9),
+	}
+
+	want := []*models.Issue{issues[1], issues[2], issues[3], issues[4]}
+	if got := onChangedLines(issues, files); !reflect.DeepEqual(got, want) {
+		t.Errorf("onChangedLines() = %v, want %v", got, want)
+	}
+}
@@ -0,0 +1,44 @@
+package analyzer
+
+import (
+	"context"
+	"fmt"
+	"log"
+
+	"github.com/keploy/keploy-review-agent/internal/config"
+	"github.com/keploy/keploy-review-agent/internal/formatter"
+	"github.com/keploy/keploy-review-agent/internal/repoconfig"
+	"github.com/keploy/keploy-review-agent/pkg/models"
+)
+
+// postWalkthrough summarizes the pull request with the LLM and posts the
+// walkthrough as a sticky comment or in a marked section of the pull request
+// description, depending on the settings.
+func (o *Orchestrator) postWalkthrough(ctx context.Context, job *Job, files []*models.File, settings *repoconfig.Settings) error {
+	pr, err := o.githubClient.GetPullRequest(ctx, job.RepoOwner, job.RepoName, job.PRNumber)
+	if err != nil {
+		return fmt.Errorf("failed to fetch pull request: %w", err)
+	}
+
+	walkthrough, err := o.aiAnalyzerFor(settings).Summarize(ctx, pr.Title, formatter.StripWalkthroughSection(pr.Body), files)
+	if err != nil {
+		return fmt.Errorf("failed to summarize pull request: %w", err)
+	}
+	body := formatter.FormatWalkthrough(walkthrough)
+
+	if settings.SummaryTarget == config.SummaryTargetDescription {
+		updated := formatter.ReplaceWalkthroughSection(pr.Body, body)
+		if updated == pr.Body {
+			return nil
+		}
+		if err := o.githubClient.UpdatePullRequestBody(ctx, job.RepoOwner, job.RepoName, job.PRNumber, updated); err != nil {
+			return err
+		}
+	} else if err := o.githubClient.UpsertIssueComment(ctx, job.RepoOwner, job.RepoName, job.PRNumber, formatter.WalkthroughMarker, body); err != nil {
+		return err
+	}
+
+	log.Printf("Posted walkthrough for %s/%s PR #%d to the pull request %s",
+		job.RepoOwner, job.RepoName, job.PRNumber, settings.SummaryTarget)
+	return nil
+}
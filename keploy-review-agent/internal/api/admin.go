@@ -0,0 +1,133 @@
+package api
+
+import (
+	"crypto/subtle"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/keploy/keploy-review-agent/internal/cache"
+	"github.com/keploy/keploy-review-agent/internal/config"
+	"github.com/keploy/keploy-review-agent/internal/feedback"
+	"github.com/keploy/keploy-review-agent/internal/jobs"
+)
+
+// AdminHandler serves the admin API, which exposes the active configuration
+// version, the configuration version each recent job ran with, the result
+// cache statistics and the reviewer feedback of each repository.
+type AdminHandler struct {
+	store *config.Store
+	jobs  *jobs.Registry
+}
+
+func NewAdminHandler(store *config.Store, registry *jobs.Registry) *AdminHandler {
+	return &AdminHandler{
+		store: store,
+		jobs:  registry,
+	}
+}
+
+// Authorize requires the configured admin token as a bearer token. The admin
+// API is disabled when no token is configured.
+func (h *AdminHandler) Authorize(c *gin.Context) {
+	token := h.store.Current().Config.AdminToken
+	if token == "" {
+		c.AbortWithStatusJSON(http.StatusForbidden, gin.H{"error": "admin API is disabled"})
+		return
+	}
+
+	provided := strings.TrimPrefix(c.GetHeader("Authorization"), "Bearer ")
+	if subtle.ConstantTimeCompare([]byte(provided), []byte(token)) != 1 {
+		c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{"error": "invalid admin token"})
+		return
+	}
+	c.Next()
+}
+
+func (h *AdminHandler) GetConfig(c *gin.Context) {
+	snapshot := h.store.Current()
+	c.JSON(http.StatusOK, gin.H{
+		"version":   snapshot.Version,
+		"loaded_at": snapshot.LoadedAt,
+		"path":      h.store.Path(),
+	})
+}
+
+// ReloadConfig reloads the configuration, keeping the current version when
+// the new configuration is invalid.
+func (h *AdminHandler) ReloadConfig(c *gin.Context) {
+	snapshot, err := h.store.Reload()
+	if err != nil {
+		c.JSON(http.StatusUnprocessableEntity, gin.H{
+			"error":   err.Error(),
+			"version": snapshot.Version,
+		})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{
+		"version":   snapshot.Version,
+		"loaded_at": snapshot.LoadedAt,
+	})
+}
+
+// GetCacheStats reports the hit and miss counts of the result cache.
+func (h *AdminHandler) GetCacheStats(c *gin.Context) {
+	cfg := h.store.Current().Config
+	if !cfg.CacheEnabled {
+		c.JSON(http.StatusOK, gin.H{"enabled": false})
+		return
+	}
+
+	stats := cache.Stats{MaxBytes: cfg.CacheMaxBytes}
+	if resultCache := cache.Lookup(cfg.CacheDir); resultCache != nil {
+		stats = resultCache.Stats()
+	}
+	c.JSON(http.StatusOK, gin.H{
+		"enabled": true,
+		"dir":     cfg.CacheDir,
+		"stats":   stats,
+	})
+}
+
+// GetFeedback reports the false-positive statistics of a repository and the
+// rules demoted or muted because of them.
+func (h *AdminHandler) GetFeedback(c *gin.Context) {
+	cfg := h.store.Current().Config
+	if !cfg.FeedbackEnabled {
+		c.JSON(http.StatusOK, gin.H{"enabled": false})
+		return
+	}
+
+	store, err := feedback.Open(cfg.FeedbackDir)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	repo, err := store.Load(c.Param("owner") + "/" + c.Param("repo"))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	policy := feedback.Policy{
+		MinSamples: cfg.FeedbackMinSamples,
+		DemoteRate: cfg.FeedbackDemoteRate,
+		MuteRate:   cfg.FeedbackMuteRate,
+		WindowDays: cfg.FeedbackWindowDays,
+	}
+	stats := repo.Stats(policy.Since(time.Now()))
+	c.JSON(http.StatusOK, gin.H{
+		"enabled":   true,
+		"policy":    policy,
+		"rules":     stats,
+		"decisions": policy.Decide(stats),
+	})
+}
+
+func (h *AdminHandler) ListJobs(c *gin.Context) {
+	c.JSON(http.StatusOK, gin.H{
+		"config_version": h.store.Current().Version,
+		"jobs":           h.jobs.List(),
+	})
+}
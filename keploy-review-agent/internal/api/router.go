@@ -6,9 +6,10 @@ import (
 	"github.com/gin-gonic/gin"
 	"github.com/keploy/keploy-review-agent/internal/config"
 	"github.com/keploy/keploy-review-agent/internal/event"
+	"github.com/keploy/keploy-review-agent/internal/jobs"
 )
 
-func NewRouter(cfg *config.Config) *gin.Engine {
+func NewRouter(store *config.Store, registry *jobs.Registry) *gin.Engine {
 	r := gin.Default()
 
 	r.GET("/health", func(c *gin.Context) {
@@ -17,7 +18,7 @@ func NewRouter(cfg *config.Config) *gin.Engine {
 		})
 	})
 
-	webhookHandler := event.NewWebhookHandler(cfg)
+	webhookHandler := event.NewWebhookHandler(store, registry)
 
 	r.POST("/webhook/github", webhookHandler.HandleGitHub)
 
@@ -35,6 +36,16 @@ func NewRouter(cfg *config.Config) *gin.Engine {
 			})
 		})
 	}
-	
+
+	adminHandler := NewAdminHandler(store, registry)
+	admin := r.Group("/admin", adminHandler.Authorize)
+	{
+		admin.GET("/config", adminHandler.GetConfig)
+		admin.POST("/config/reload", adminHandler.ReloadConfig)
+		admin.GET("/jobs", adminHandler.ListJobs)
+		admin.GET("/cache", adminHandler.GetCacheStats)
+		admin.GET("/feedback/:owner/:repo", adminHandler.GetFeedback)
+	}
+
 	return r
 }
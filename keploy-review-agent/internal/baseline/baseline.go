@@ -0,0 +1,175 @@
+package baseline
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/keploy/keploy-review-agent/pkg/models"
+)
+
+// FileName is the default baseline file, read from the base branch of a
+// pull request like the repository configuration.
+const FileName = ".keploy-review-baseline.json"
+
+// version is the format version written to new baselines.
+const version = 1
+
+// Entry is a grandfathered finding. Path and rule are informational, so that
+// the file can be reviewed; matching uses the fingerprint only.
+type Entry struct {
+	Fingerprint string `json:"fingerprint"`
+	Path        string `json:"path"`
+	Rule        string `json:"rule,omitempty"`
+	Line        int    `json:"line,omitempty"`
+}
+
+// Baseline is the set of findings present when a ref was scanned. Reviews
+// report only findings missing from it.
+type Baseline struct {
+	Version   int       `json:"version"`
+	Ref       string    `json:"ref"`
+	Commit    string    `json:"commit,omitempty"`
+	CreatedAt time.Time `json:"created_at"`
+	Entries   []Entry   `json:"entries"`
+
+	index map[string]bool
+}
+
+// New returns the baseline of the issues found at a commit. Issues must
+// have their fingerprints assigned.
+func New(ref, commit string, issues []*models.Issue) *Baseline {
+	b := &Baseline{Version: version, Ref: ref, Commit: commit, CreatedAt: time.Now().UTC()}
+	seen := make(map[string]bool)
+	for _, issue := range issues {
+		if issue.Fingerprint == "" || seen[issue.Fingerprint] {
+			continue
+		}
+		seen[issue.Fingerprint] = true
+		b.Entries = append(b.Entries, Entry{
+			Fingerprint: issue.Fingerprint,
+			Path:        issue.Path,
+			Rule:        issue.RuleID,
+			Line:        issue.Line,
+		})
+	}
+	sort.Slice(b.Entries, func(i, j int) bool {
+		a, c := b.Entries[i], b.Entries[j]
+		if a.Path != c.Path {
+			return a.Path < c.Path
+		}
+		if a.Line != c.Line {
+			return a.Line < c.Line
+		}
+		return a.Fingerprint < c.Fingerprint
+	})
+	b.index = seen
+	return b
+}
+
+// Parse decodes a baseline file.
+func Parse(data []byte) (*Baseline, error) {
+	var b Baseline
+	if err := json.Unmarshal(data, &b); err != nil {
+		return nil, fmt.Errorf("invalid baseline: %w", err)
+	}
+	if b.Version > version {
+		return nil, fmt.Errorf("unsupported baseline version %d", b.Version)
+	}
+	b.index = make(map[string]bool, len(b.Entries))
+	for _, e := range b.Entries {
+		if e.Fingerprint == "" {
+			return nil, fmt.Errorf("invalid baseline: entry for %s has no fingerprint", e.Path)
+		}
+		b.index[e.Fingerprint] = true
+	}
+	return &b, nil
+}
+
+// Write saves the baseline to path, replacing any existing file atomically.
+func (b *Baseline) Write(path string) error {
+	data, err := json.MarshalIndent(b, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to encode baseline: %w", err)
+	}
+	data = append(data, '\n')
+
+	tmp := filepath.Join(filepath.Dir(path), "."+filepath.Base(path)+".tmp")
+	if err := os.WriteFile(tmp, data, 0o644); err != nil {
+		return fmt.Errorf("failed to write baseline: %w", err)
+	}
+	if err := os.Rename(tmp, path); err != nil {
+		os.Remove(tmp)
+		return fmt.Errorf("failed to write baseline: %w", err)
+	}
+	return nil
+}
+
+// Contains reports whether a fingerprint is grandfathered.
+func (b *Baseline) Contains(fingerprint string) bool {
+	return b != nil && b.index[fingerprint]
+}
+
+// Result is the outcome of applying a baseline to a review.
+type Result struct {
+	Suppressed int // findings dropped because they are in the baseline
+	Fixed      int // baseline entries in reviewed files that were not found again
+}
+
+// Apply drops the issues in the baseline. Entries of the reviewed paths that
+// no issue matches any more are counted as fixed, except entries of rules
+// for which recheck returns false, such as rules of an analyzer that only
+// looked at the changed lines.
+func (b *Baseline) Apply(issues []*models.Issue, reviewed []string, recheck func(rule string) bool) ([]*models.Issue, Result) {
+	var result Result
+	if b == nil || len(b.Entries) == 0 {
+		return issues, result
+	}
+
+	found := make(map[string]bool)
+	var kept []*models.Issue
+	for _, issue := range issues {
+		if b.Contains(issue.Fingerprint) {
+			found[issue.Fingerprint] = true
+			result.Suppressed++
+			continue
+		}
+		kept = append(kept, issue)
+	}
+
+	paths := make(map[string]bool, len(reviewed))
+	for _, path := range reviewed {
+		paths[path] = true
+	}
+	for _, e := range b.Entries {
+		if paths[e.Path] && !found[e.Fingerprint] && (recheck == nil || recheck(e.Rule)) {
+			result.Fixed++
+		}
+	}
+	return kept, result
+}
+
+// String summarizes the result for the review summary, or returns an empty
+// string when the baseline played no part in the review.
+func (r Result) String() string {
+	var parts []string
+	if r.Suppressed > 0 {
+		parts = append(parts, fmt.Sprintf("%d baselined %s not reported", r.Suppressed, plural(r.Suppressed, "finding", "findings")))
+	}
+	if r.Fixed > 0 {
+		parts = append(parts, fmt.Sprintf("%d baselined %s fixed; regenerate the baseline to shrink it",
+			r.Fixed, plural(r.Fixed, "finding", "findings")))
+	}
+	return strings.Join(parts, ". ")
+}
+
+func plural(n int, one, many string) string {
+	if n == 1 {
+		return one
+	}
+	return many
+}
@@ -0,0 +1,252 @@
+package cache
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// Key identifies a cached analysis result. Results are content-addressed:
+// the same file content at the same path analyzed by the same analyzer
+// version and model always maps to the same entry. The path is part of the
+// key because results name their file and analyzers choose their rules by
+// path.
+type Key struct {
+	BlobSHA  string // Git blob SHA of the file content
+	Path     string
+	Analyzer string
+	Version  string // analyzer configuration and prompt version
+	Model    string // empty for analyzers not using a model
+}
+
+func (k Key) hash() string {
+	sum := sha256.Sum256([]byte(strings.Join([]string{k.BlobSHA, k.Path, k.Analyzer, k.Version, k.Model}, "\x00")))
+	return hex.EncodeToString(sum[:])
+}
+
+// Cache stores analysis results on local disk, one JSON file per entry.
+// Entries expire after the TTL, and the oldest entries are evicted when the
+// total size exceeds the size cap.
+type Cache struct {
+	dir string
+
+	mu       sync.Mutex // guards the settings, size and eviction
+	ttl      time.Duration
+	maxBytes int64
+	size     int64
+
+	hits      atomic.Int64
+	misses    atomic.Int64
+	evictions atomic.Int64
+}
+
+// Stats describes the use of a cache since the process started.
+type Stats struct {
+	Hits      int64   `json:"hits"`
+	Misses    int64   `json:"misses"`
+	HitRate   float64 `json:"hit_rate"`
+	Evictions int64   `json:"evictions"`
+	Bytes     int64   `json:"bytes"`
+	MaxBytes  int64   `json:"max_bytes"`
+}
+
+var (
+	openMu sync.Mutex
+	opened = make(map[string]*Cache)
+)
+
+// Open returns the cache stored in dir, creating the directory if needed.
+// Every caller opening the same directory shares one cache, so statistics
+// and the size cap hold across reviews and configuration reloads.
+func Open(dir string, ttl time.Duration, maxBytes int64) (*Cache, error) {
+	openMu.Lock()
+	defer openMu.Unlock()
+
+	dir = filepath.Clean(dir)
+	if c, ok := opened[dir]; ok {
+		c.mu.Lock()
+		c.ttl, c.maxBytes = ttl, maxBytes
+		c.mu.Unlock()
+		return c, nil
+	}
+
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return nil, fmt.Errorf("failed to create cache directory: %w", err)
+	}
+	c := &Cache{dir: dir, ttl: ttl, maxBytes: maxBytes}
+	for _, e := range c.entries() {
+		c.size += e.size
+	}
+	opened[dir] = c
+	return c, nil
+}
+
+// Lookup returns the cache opened for dir, or nil if it was not opened.
+func Lookup(dir string) *Cache {
+	openMu.Lock()
+	defer openMu.Unlock()
+	return opened[filepath.Clean(dir)]
+}
+
+// Get decodes the entry for key into v and reports whether it was found.
+func (c *Cache) Get(key Key, v interface{}) bool {
+	path := c.path(key)
+	info, err := os.Stat(path)
+	if err != nil {
+		c.misses.Add(1)
+		return false
+	}
+
+	c.mu.Lock()
+	ttl := c.ttl
+	c.mu.Unlock()
+	if ttl > 0 && time.Since(info.ModTime()) > ttl {
+		c.remove(path, info.Size())
+		c.misses.Add(1)
+		return false
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil || json.Unmarshal(data, v) != nil {
+		c.misses.Add(1)
+		return false
+	}
+	c.hits.Add(1)
+	return true
+}
+
+// Put stores v as the entry for key.
+func (c *Cache) Put(key Key, v interface{}) error {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return fmt.Errorf("failed to encode cache entry: %w", err)
+	}
+
+	path := c.path(key)
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return fmt.Errorf("failed to create cache directory: %w", err)
+	}
+	// Write to a temporary file first so readers never see partial entries.
+	// Every writer has its own, as reviews may store the same entry at once.
+	f, err := os.CreateTemp(filepath.Dir(path), filepath.Base(path)+".*.tmp")
+	if err != nil {
+		return fmt.Errorf("failed to write cache entry: %w", err)
+	}
+	tmp := f.Name()
+	_, err = f.Write(data)
+	if closeErr := f.Close(); err == nil {
+		err = closeErr
+	}
+	if err == nil {
+		err = os.Chmod(tmp, 0o644)
+	}
+	if err != nil {
+		os.Remove(tmp)
+		return fmt.Errorf("failed to write cache entry: %w", err)
+	}
+	var previous int64
+	if info, err := os.Stat(path); err == nil {
+		previous = info.Size()
+	}
+	if err := os.Rename(tmp, path); err != nil {
+		os.Remove(tmp)
+		return fmt.Errorf("failed to write cache entry: %w", err)
+	}
+
+	c.mu.Lock()
+	c.size += int64(len(data)) - previous
+	over := c.maxBytes > 0 && c.size > c.maxBytes
+	c.mu.Unlock()
+	if over {
+		c.evict()
+	}
+	return nil
+}
+
+func (c *Cache) Stats() Stats {
+	c.mu.Lock()
+	size, maxBytes := c.size, c.maxBytes
+	c.mu.Unlock()
+
+	stats := Stats{
+		Hits:      c.hits.Load(),
+		Misses:    c.misses.Load(),
+		Evictions: c.evictions.Load(),
+		Bytes:     size,
+		MaxBytes:  maxBytes,
+	}
+	if total := stats.Hits + stats.Misses; total > 0 {
+		stats.HitRate = float64(stats.Hits) / float64(total)
+	}
+	return stats
+}
+
+func (c *Cache) path(key Key) string {
+	h := key.hash()
+	return filepath.Join(c.dir, h[:2], h+".json")
+}
+
+type entry struct {
+	path    string
+	size    int64
+	modTime time.Time
+}
+
+func (c *Cache) entries() []entry {
+	var entries []entry
+	filepath.Walk(c.dir, func(path string, info os.FileInfo, err error) error {
+		if err == nil && !info.IsDir() && strings.HasSuffix(path, ".json") {
+			entries = append(entries, entry{path: path, size: info.Size(), modTime: info.ModTime()})
+		}
+		return nil
+	})
+	return entries
+}
+
+// evict removes expired entries and then the oldest ones until the cache is
+// below 90% of its size cap, leaving room for new entries.
+func (c *Cache) evict() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	entries := c.entries()
+	sort.Slice(entries, func(i, j int) bool { return entries[i].modTime.Before(entries[j].modTime) })
+
+	var size int64
+	for _, e := range entries {
+		size += e.size
+	}
+	target := c.maxBytes * 9 / 10
+	for _, e := range entries {
+		expired := c.ttl > 0 && time.Since(e.modTime) > c.ttl
+		if size <= target && !expired {
+			break
+		}
+		if err := os.Remove(e.path); err != nil {
+			log.Printf("Warning: Failed to evict cache entry %s: %v", e.path, err)
+			continue
+		}
+		size -= e.size
+		c.evictions.Add(1)
+	}
+	c.size = size
+}
+
+func (c *Cache) remove(path string, size int64) {
+	if err := os.Remove(path); err != nil {
+		return
+	}
+	c.mu.Lock()
+	c.size -= size
+	c.mu.Unlock()
+	c.evictions.Add(1)
+}
@@ -0,0 +1,191 @@
+package cache
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestGetPut(t *testing.T) {
+	c, err := Open(t.TempDir(), time.Hour, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	key := Key{BlobSHA: "abc", Analyzer: "static", Version: "1"}
+
+	var got []string
+	if c.Get(key, &got) {
+		t.Fatal("Get() found an entry in an empty cache")
+	}
+	if err := c.Put(key, []string{"issue"}); err != nil {
+		t.Fatal(err)
+	}
+	if !c.Get(key, &got) || len(got) != 1 || got[0] != "issue" {
+		t.Fatalf("Get() = %v, want [issue]", got)
+	}
+
+	for _, other := range []Key{
+		{BlobSHA: "def", Analyzer: "static", Version: "1"},
+		{BlobSHA: "abc", Path: "b.go", Analyzer: "static", Version: "1"},
+		{BlobSHA: "abc", Analyzer: "llm", Version: "1"},
+		{BlobSHA: "abc", Analyzer: "static", Version: "2"},
+		{BlobSHA: "abc", Analyzer: "static", Version: "1", Model: "m"},
+	} {
+		if c.Get(other, &got) {
+			t.Errorf("Get(%+v) found the entry of %+v", other, key)
+		}
+	}
+
+	if stats := c.Stats(); stats.Hits != 1 || stats.Misses != 6 {
+		t.Errorf("Stats() = %+v, want 1 hit and 6 misses", stats)
+	}
+}
+
+func TestTTL(t *testing.T) {
+	tests := []struct {
+		name string
+		ttl  time.Duration
+		age  time.Duration
+		want bool
+	}{
+		{"fresh", time.Hour, time.Minute, true},
+		{"expired", time.Hour, 2 * time.Hour, false},
+		{"no TTL", 0, 1000 * time.Hour, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c, err := Open(t.TempDir(), tt.ttl, 0)
+			if err != nil {
+				t.Fatal(err)
+			}
+			key := Key{BlobSHA: "abc", Analyzer: "static"}
+			if err := c.Put(key, "value"); err != nil {
+				t.Fatal(err)
+			}
+			modTime := time.Now().Add(-tt.age)
+			if err := os.Chtimes(c.path(key), modTime, modTime); err != nil {
+				t.Fatal(err)
+			}
+
+			var got string
+			if found := c.Get(key, &got); found != tt.want {
+				t.Fatalf("Get() = %t, want %t", found, tt.want)
+			}
+			if _, err := os.Stat(c.path(key)); tt.want != (err == nil) {
+				t.Errorf("entry exists: %t, want %t", err == nil, tt.want)
+			}
+		})
+	}
+}
+
+func TestEviction(t *testing.T) {
+	c, err := Open(t.TempDir(), 0, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	value := strings.Repeat("x", 100)
+	var keys []Key
+	for i := 0; i < 5; i++ {
+		key := Key{BlobSHA: fmt.Sprint(i), Analyzer: "static"}
+		if err := c.Put(key, value); err != nil {
+			t.Fatal(err)
+		}
+		// Oldest first, whatever the resolution of the file system clock.
+		modTime := time.Now().Add(time.Duration(i-10) * time.Minute)
+		if err := os.Chtimes(c.path(key), modTime, modTime); err != nil {
+			t.Fatal(err)
+		}
+		keys = append(keys, key)
+	}
+
+	entrySize := c.Stats().Bytes / 5
+	// Lowering the cap takes effect on the next write, like a reload.
+	c, err = Open(c.dir, 0, 5*entrySize)
+	if err != nil {
+		t.Fatal(err)
+	}
+	last := Key{BlobSHA: "5", Analyzer: "static"}
+	if err := c.Put(last, value); err != nil {
+		t.Fatal(err)
+	}
+
+	// Eviction goes below 90% of the cap: two entries are removed.
+	var got string
+	for i, key := range append(keys, last) {
+		want := i >= 2
+		if found := c.Get(key, &got); found != want {
+			t.Errorf("entry %d found: %t, want %t", i, found, want)
+		}
+	}
+	stats := c.Stats()
+	if stats.Evictions != 2 {
+		t.Errorf("Evictions = %d, want 2", stats.Evictions)
+	}
+	if stats.Bytes != 4*entrySize {
+		t.Errorf("Bytes = %d, want %d", stats.Bytes, 4*entrySize)
+	}
+}
+
+func TestConcurrentPut(t *testing.T) {
+	c, err := Open(t.TempDir(), time.Hour, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	key := Key{BlobSHA: "abc", Analyzer: "static"}
+	value := strings.Repeat("x", 64*1024)
+
+	var wg sync.WaitGroup
+	errs := make(chan error, 20)
+	for i := 0; i < 20; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if err := c.Put(key, value); err != nil {
+				errs <- err
+			}
+			var got string
+			if c.Get(key, &got) && got != value {
+				errs <- fmt.Errorf("read a partial entry of %d bytes", len(got))
+			}
+		}()
+	}
+	wg.Wait()
+	close(errs)
+	for err := range errs {
+		t.Error(err)
+	}
+
+	matches, err := filepath.Glob(filepath.Join(filepath.Dir(c.path(key)), "*.tmp"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(matches) > 0 {
+		t.Errorf("temporary files left behind: %v", matches)
+	}
+}
+
+func TestOpenShared(t *testing.T) {
+	dir := t.TempDir()
+	c1, err := Open(dir, time.Hour, 100)
+	if err != nil {
+		t.Fatal(err)
+	}
+	c2, err := Open(dir+string(filepath.Separator), 2*time.Hour, 200)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if c1 != c2 {
+		t.Fatal("Open() returned different caches for the same directory")
+	}
+	if c1.ttl != 2*time.Hour || c1.maxBytes != 200 {
+		t.Errorf("settings = %v, %d; want the latest ones", c1.ttl, c1.maxBytes)
+	}
+	if Lookup(dir) != c1 {
+		t.Error("Lookup() did not return the opened cache")
+	}
+}
@@ -0,0 +1,152 @@
+package command
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// Command names.
+const (
+	Review  = "review"
+	Explain = "explain"
+	Ignore  = "ignore"
+	Summary = "summary"
+)
+
+// Repository permissions, from the least to the most privileged, as reported
+// by the GitHub collaborator permission API.
+var permissionRanks = map[string]int{
+	"none":     0,
+	"read":     1,
+	"triage":   2,
+	"write":    3,
+	"maintain": 4,
+	"admin":    5,
+}
+
+// Usage describes the supported commands.
+const Usage = "Supported commands: `/review`, `/review full`, `/review llm off`, " +
+	"`/explain <file>:<line>`, `/ignore <rule>` and `/summary`."
+
+var ruleIDRegex = regexp.MustCompile(`^[A-Za-z0-9_./:@-]+$`)
+
+// Command is a slash command parsed from a pull request comment.
+type Command struct {
+	Name string
+	Text string // the command line as written
+
+	Full    bool // review whole files instead of only the changed lines
+	SkipLLM bool // review without the LLM analyzer
+
+	Path string // file and line to explain
+	Line int
+
+	Rule string // rule to ignore
+}
+
+// Parse returns the first command found at the start of a line of the
+// comment, or nil when there is none. Lines starting with an unknown command
+// are left alone, since they may be meant for other bots.
+func Parse(body string) (*Command, error) {
+	for _, line := range strings.Split(body, "\n") {
+		fields := strings.Fields(line)
+		if len(fields) == 0 || !strings.HasPrefix(fields[0], "/") {
+			continue
+		}
+
+		cmd := &Command{Name: strings.ToLower(strings.TrimPrefix(fields[0], "/")), Text: strings.Join(fields, " ")}
+		args := fields[1:]
+		switch cmd.Name {
+		case Review:
+			return cmd, cmd.parseReview(args)
+		case Explain:
+			return cmd, cmd.parseExplain(args)
+		case Ignore:
+			return cmd, cmd.parseIgnore(args)
+		case Summary:
+			if len(args) > 0 {
+				return cmd, fmt.Errorf("`/summary` takes no arguments")
+			}
+			return cmd, nil
+		}
+	}
+	return nil, nil
+}
+
+func (c *Command) parseReview(args []string) error {
+	for i := 0; i < len(args); i++ {
+		switch strings.ToLower(args[i]) {
+		case "full":
+			c.Full = true
+		case "llm":
+			if i+1 == len(args) {
+				return fmt.Errorf("`/review llm` expects `on` or `off`")
+			}
+			i++
+			switch strings.ToLower(args[i]) {
+			case "off":
+				c.SkipLLM = true
+			case "on":
+				c.SkipLLM = false
+			default:
+				return fmt.Errorf("`/review llm` expects `on` or `off`, got %q", args[i])
+			}
+		default:
+			return fmt.Errorf("unknown `/review` option %q", args[i])
+		}
+	}
+	return nil
+}
+
+func (c *Command) parseExplain(args []string) error {
+	if len(args) != 1 {
+		return fmt.Errorf("`/explain` expects a single `<file>:<line>` argument")
+	}
+	i := strings.LastIndex(args[0], ":")
+	if i <= 0 {
+		return fmt.Errorf("`/explain` expects `<file>:<line>`, got %q", args[0])
+	}
+	line, err := strconv.Atoi(args[0][i+1:])
+	if err != nil || line < 1 {
+		return fmt.Errorf("`/explain` expects a positive line number, got %q", args[0][i+1:])
+	}
+	c.Path = strings.TrimPrefix(args[0][:i], "./")
+	c.Line = line
+	return nil
+}
+
+func (c *Command) parseIgnore(args []string) error {
+	if len(args) != 1 {
+		return fmt.Errorf("`/ignore` expects a single rule ID")
+	}
+	if !ruleIDRegex.MatchString(args[0]) {
+		return fmt.Errorf("invalid rule ID %q", args[0])
+	}
+	c.Rule = args[0]
+	return nil
+}
+
+// Permission returns the repository permission needed to run the command.
+// Commands that change the review or spend analysis budget need write
+// access; explaining code only needs read access.
+func (c *Command) Permission() string {
+	if c.Name == Explain {
+		return "read"
+	}
+	return "write"
+}
+
+// Allowed reports whether a user with the given permission may run the
+// command.
+func (c *Command) Allowed(permission string) bool {
+	return HasPermission(permission, c.Permission())
+}
+
+// HasPermission reports whether a repository role grants at least the
+// required one.
+func HasPermission(permission, required string) bool {
+	rank, ok := permissionRanks[permission]
+	return ok && rank >= permissionRanks[required]
+}
@@ -0,0 +1,149 @@
+package command
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestParse(t *testing.T) {
+	tests := []struct {
+		name    string
+		body    string
+		want    *Command
+		wantErr bool
+	}{
+		{
+			name: "no command",
+			body: "Looks good to me.\nThanks!",
+		},
+		{
+			name: "unknown commands are left to other bots",
+			body: "/deploy staging",
+		},
+		{
+			name: "review",
+			body: "/review",
+			want: &Command{Name: Review, Text: "/review"},
+		},
+		{
+			name: "review full without the LLM",
+			body: "/Review FULL llm off",
+			want: &Command{Name: Review, Text: "/Review FULL llm off", Full: true, SkipLLM: true},
+		},
+		{
+			name: "review with the LLM",
+			body: "/review llm on",
+			want: &Command{Name: Review, Text: "/review llm on"},
+		},
+		{
+			name:    "review llm without a value",
+			body:    "/review llm",
+			want:    &Command{Name: Review, Text: "/review llm"},
+			wantErr: true,
+		},
+		{
+			name:    "review with an unknown option",
+			body:    "/review everything",
+			want:    &Command{Name: Review, Text: "/review everything"},
+			wantErr: true,
+		},
+		{
+			name: "command on a later line",
+			body: "Fixed, thanks.\n  /explain ./pkg/a.go:12  ",
+			want: &Command{Name: Explain, Text: "/explain ./pkg/a.go:12", Path: "pkg/a.go", Line: 12},
+		},
+		{
+			name: "only the first command counts",
+			body: "/summary\n/review",
+			want: &Command{Name: Summary, Text: "/summary"},
+		},
+		{
+			name:    "explain without a line",
+			body:    "/explain pkg/a.go",
+			want:    &Command{Name: Explain, Text: "/explain pkg/a.go"},
+			wantErr: true,
+		},
+		{
+			name:    "explain with an invalid line",
+			body:    "/explain pkg/a.go:0",
+			want:    &Command{Name: Explain, Text: "/explain pkg/a.go:0"},
+			wantErr: true,
+		},
+		{
+			name: "ignore",
+			body: "/ignore @typescript-eslint/no-unused-vars",
+			want: &Command{Name: Ignore, Text: "/ignore @typescript-eslint/no-unused-vars", Rule: "@typescript-eslint/no-unused-vars"},
+		},
+		{
+			name:    "ignore an invalid rule",
+			body:    "/ignore <script>",
+			want:    &Command{Name: Ignore, Text: "/ignore <script>"},
+			wantErr: true,
+		},
+		{
+			name:    "ignore without a rule",
+			body:    "/ignore",
+			want:    &Command{Name: Ignore, Text: "/ignore"},
+			wantErr: true,
+		},
+		{
+			name:    "summary with arguments",
+			body:    "/summary now",
+			want:    &Command{Name: Summary, Text: "/summary now"},
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := Parse(tt.body)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Parse() error = %v, wantErr %t", err, tt.wantErr)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Parse() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestAllowed(t *testing.T) {
+	tests := []struct {
+		command    string
+		permission string
+		want       bool
+	}{
+		{"/explain a.go:1", "read", true},
+		{"/explain a.go:1", "none", false},
+		{"/review", "triage", false},
+		{"/review", "write", true},
+		{"/ignore S1000", "admin", true},
+		{"/summary", "custom-role", false},
+	}
+	for _, tt := range tests {
+		cmd, err := Parse(tt.command)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got := cmd.Allowed(tt.permission); got != tt.want {
+			t.Errorf("%q allowed with %s = %t, want %t", tt.command, tt.permission, got, tt.want)
+		}
+	}
+}
+
+func TestHasPermission(t *testing.T) {
+	tests := []struct {
+		permission, required string
+		want                 bool
+	}{
+		{"triage", "triage", true},
+		{"maintain", "triage", true},
+		{"read", "triage", false},
+		{"", "none", false},
+	}
+	for _, tt := range tests {
+		if got := HasPermission(tt.permission, tt.required); got != tt.want {
+			t.Errorf("HasPermission(%q, %q) = %t, want %t", tt.permission, tt.required, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,305 @@
+package config
+
+import (
+	"fmt"
+	"os"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/keploy/keploy-review-agent/pkg/models"
+)
+
+var envRefRegex = regexp.MustCompile(`^\$\{([A-Za-z_][A-Za-z0-9_]*)\}$`)
+
+// FileConfig is the schema of the server configuration file. Every field is
+// optional; unset fields keep the values of Default.
+type FileConfig struct {
+	Server struct {
+		Port              *int    `yaml:"port"`
+		MaxFileSizeBytes  *int64  `yaml:"max_file_size_bytes"`
+		MaxProcessingTime *int    `yaml:"max_processing_time_seconds"`
+		ReportPath        *string `yaml:"report_path"`
+		AdminToken        Secret  `yaml:"admin_token"`
+	} `yaml:"server"`
+
+	GitHub struct {
+		Token    Secret  `yaml:"token"`
+		BotLogin *string `yaml:"bot_login"`
+		AppID    *int64  `yaml:"app_id"`
+
+		WebhookSecret Secret `yaml:"webhook_secret"`
+	} `yaml:"github"`
+
+	GitLab struct {
+		Token Secret `yaml:"token"`
+	} `yaml:"gitlab"`
+
+	LLM struct {
+		Enabled           *bool            `yaml:"enabled"`
+		Provider          *string          `yaml:"provider"`
+		Model             *string          `yaml:"model"`
+		ContextWindow     *int             `yaml:"context_window"`
+		DiffContext       *int             `yaml:"diff_context"`
+		ChunkTokens       *int             `yaml:"chunk_tokens"`
+		PRTokenBudget     *int             `yaml:"pr_token_budget"`
+		Concurrency       *int             `yaml:"concurrency"`
+		RequestsPerMinute *int             `yaml:"requests_per_minute"`
+		TokensPerMinute   *int             `yaml:"tokens_per_minute"`
+		ProviderURL       *string          `yaml:"provider_url"`
+		APIKey            Secret           `yaml:"api_key"`
+		GoogleAIKey       Secret           `yaml:"google_ai_key"`
+		MaxTokens         *int             `yaml:"max_tokens"`
+		Temperature       *float64         `yaml:"temperature"`
+		MinSeverity       *models.Severity `yaml:"min_severity"`
+	} `yaml:"llm"`
+
+	Summary struct {
+		Enabled *bool   `yaml:"enabled"`
+		Target  *string `yaml:"target"`
+	} `yaml:"summary"`
+
+	Analyzers struct {
+		Static     *bool `yaml:"static"`
+		Dependency *bool `yaml:"dependency"`
+	} `yaml:"analyzers"`
+
+	Dependency struct {
+		MinCVSS *float64 `yaml:"min_cvss"`
+	} `yaml:"dependency"`
+
+	Severity struct {
+		Min    *models.Severity `yaml:"min"`
+		FailOn *models.Severity `yaml:"fail_on"`
+	} `yaml:"severity"`
+
+	Cache struct {
+		Enabled    *bool   `yaml:"enabled"`
+		Dir        *string `yaml:"dir"`
+		TTLSeconds *int    `yaml:"ttl_seconds"`
+		MaxBytes   *int64  `yaml:"max_bytes"`
+	} `yaml:"cache"`
+
+	Feedback struct {
+		Enabled    *bool    `yaml:"enabled"`
+		Dir        *string  `yaml:"dir"`
+		MinSamples *int     `yaml:"min_samples"`
+		DemoteRate *float64 `yaml:"demote_rate"`
+		MuteRate   *float64 `yaml:"mute_rate"`
+		WindowDays *int     `yaml:"window_days"`
+	} `yaml:"feedback"`
+
+	QualityGateFile *string `yaml:"quality_gate_file"`
+
+	StaticAnalysis struct {
+		Go struct {
+			EnabledLinters  []string `yaml:"enabled_linters"`
+			DisabledLinters []string `yaml:"disabled_linters"`
+			StrictMode      *bool    `yaml:"strict_mode"`
+			GolangCILint    *string  `yaml:"golangci_lint_path"`
+			Engine          *string  `yaml:"engine"`
+		} `yaml:"go"`
+		TypeScript struct {
+			Enabled           *bool   `yaml:"enabled"`
+			ESLintConfig      *string `yaml:"eslint_config"`
+			TypeCheck         *bool   `yaml:"typecheck"`
+			TypeCheckAllLines *bool   `yaml:"typecheck_all_lines"`
+			TypeCheckScript   *bool   `yaml:"typecheck_script"`
+		} `yaml:"typescript"`
+		GenerateGithubActions *bool   `yaml:"generate_github_actions"`
+		Checkout              *string `yaml:"checkout"`
+	} `yaml:"static_analysis"`
+}
+
+// Secret is a reference to a secret value: "${NAME}" reads an environment
+// variable and "file:/path" reads a file. Literal secrets are rejected so
+// that keys never end up in configuration files.
+type Secret string
+
+// Resolve returns the referenced secret value. An empty reference resolves
+// to an empty value.
+func (s Secret) Resolve() (string, error) {
+	ref := strings.TrimSpace(string(s))
+	switch {
+	case ref == "":
+		return "", nil
+	case envRefRegex.MatchString(ref):
+		name := envRefRegex.FindStringSubmatch(ref)[1]
+		value, ok := os.LookupEnv(name)
+		if !ok || value == "" {
+			return "", fmt.Errorf("environment variable %s is not set", name)
+		}
+		return value, nil
+	case strings.HasPrefix(ref, "file:"):
+		data, err := os.ReadFile(strings.TrimPrefix(ref, "file:"))
+		if err != nil {
+			return "", fmt.Errorf("failed to read secret file: %w", err)
+		}
+		return strings.TrimSpace(string(data)), nil
+	default:
+		return "", fmt.Errorf("must be a ${ENV_VAR} or file:/path reference, not a literal value")
+	}
+}
+
+// LoadFile loads and validates a YAML server configuration file. All
+// problems in the file are reported together. The overrides are applied
+// before validation.
+func LoadFile(path string, overrides ...func(*Config)) (*Config, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read configuration file: %w", err)
+	}
+
+	var fc FileConfig
+	problems := DecodeYAML(data, &fc)
+
+	config, resolveProblems := fc.toConfig()
+	problems = append(problems, resolveProblems...)
+	for _, override := range overrides {
+		override(config)
+	}
+	problems = append(problems, config.Validate()...)
+	if len(problems) > 0 {
+		return nil, &ValidationError{Problems: problems}
+	}
+	return config, nil
+}
+
+func (fc *FileConfig) toConfig() (*Config, []string) {
+	config := Default()
+	var problems []string
+
+	resolve := func(field string, secret Secret, dst *string) {
+		value, err := secret.Resolve()
+		if err != nil {
+			problems = append(problems, fmt.Sprintf("%s: %v", field, err))
+			return
+		}
+		if value != "" {
+			*dst = value
+		}
+	}
+
+	if fc.Server.Port != nil {
+		config.ServerPort = strconv.Itoa(*fc.Server.Port)
+	}
+	setInt64(&config.MaxFileSizeBytes, fc.Server.MaxFileSizeBytes)
+	setInt(&config.MaxProcessingTime, fc.Server.MaxProcessingTime)
+	setString(&config.ReportPath, fc.Server.ReportPath)
+
+	resolve("server.admin_token", fc.Server.AdminToken, &config.AdminToken)
+	resolve("github.token", fc.GitHub.Token, &config.GitHubToken)
+	setString(&config.GitHubBotLogin, fc.GitHub.BotLogin)
+	setInt64(&config.GitHubAppID, fc.GitHub.AppID)
+	resolve("github.webhook_secret", fc.GitHub.WebhookSecret, &config.GitHubWebhookSecret)
+	resolve("gitlab.token", fc.GitLab.Token, &config.GitLabToken)
+
+	resolve("llm.google_ai_key", fc.LLM.GoogleAIKey, &config.GoogleAIKey)
+	resolve("llm.api_key", fc.LLM.APIKey, &config.LLMApiKey)
+	if config.LLMApiKey == "" {
+		config.LLMApiKey = config.GoogleAIKey
+	}
+	if config.GoogleAIKey == "" {
+		config.GoogleAIKey = config.LLMApiKey
+	}
+	setString(&config.LLMProvider, fc.LLM.Provider)
+	setString(&config.LLMModel, fc.LLM.Model)
+	setString(&config.LLMProviderURL, fc.LLM.ProviderURL)
+	setInt(&config.LLMContextWindow, fc.LLM.ContextWindow)
+	setInt(&config.LLMDiffContext, fc.LLM.DiffContext)
+	setInt(&config.LLMChunkTokens, fc.LLM.ChunkTokens)
+	setInt(&config.LLMPRTokenBudget, fc.LLM.PRTokenBudget)
+	setInt(&config.LLMConcurrency, fc.LLM.Concurrency)
+	setInt(&config.LLMRequestsPerMinute, fc.LLM.RequestsPerMinute)
+	setInt(&config.LLMTokensPerMinute, fc.LLM.TokensPerMinute)
+	config.EnableLLM = config.llmConfigured()
+	setBool(&config.EnableLLM, fc.LLM.Enabled)
+	config.EnableAI = config.EnableLLM
+	setInt(&config.AIMaxTokens, fc.LLM.MaxTokens)
+	if fc.LLM.Temperature != nil {
+		config.AITemperature = *fc.LLM.Temperature
+	}
+	setSeverity(&config.AIMinSeverity, fc.LLM.MinSeverity)
+	setBool(&config.SummaryEnabled, fc.Summary.Enabled)
+	setString(&config.SummaryTarget, fc.Summary.Target)
+
+	setBool(&config.EnableStaticAnalysis, fc.Analyzers.Static)
+	setBool(&config.EnableDependencyCheck, fc.Analyzers.Dependency)
+	setFloat(&config.DependencyMinCVSS, fc.Dependency.MinCVSS)
+
+	setSeverity(&config.MinSeverity, fc.Severity.Min)
+	setSeverity(&config.FailOnSeverity, fc.Severity.FailOn)
+	setString(&config.QualityGateFile, fc.QualityGateFile)
+
+	setBool(&config.CacheEnabled, fc.Cache.Enabled)
+	setString(&config.CacheDir, fc.Cache.Dir)
+	setInt(&config.CacheTTL, fc.Cache.TTLSeconds)
+	setInt64(&config.CacheMaxBytes, fc.Cache.MaxBytes)
+
+	setBool(&config.FeedbackEnabled, fc.Feedback.Enabled)
+	setString(&config.FeedbackDir, fc.Feedback.Dir)
+	setInt(&config.FeedbackMinSamples, fc.Feedback.MinSamples)
+	setFloat(&config.FeedbackDemoteRate, fc.Feedback.DemoteRate)
+	setFloat(&config.FeedbackMuteRate, fc.Feedback.MuteRate)
+	setInt(&config.FeedbackWindowDays, fc.Feedback.WindowDays)
+
+	sa := &config.StaticAnalysisConfig
+	sa.GoConfig.EnabledLinters = fc.StaticAnalysis.Go.EnabledLinters
+	sa.GoConfig.DisabledLinters = fc.StaticAnalysis.Go.DisabledLinters
+	setBool(&sa.GoConfig.StrictMode, fc.StaticAnalysis.Go.StrictMode)
+	setString(&sa.GoConfig.GolangCILintPath, fc.StaticAnalysis.Go.GolangCILint)
+	setString(&sa.GoConfig.Engine, fc.StaticAnalysis.Go.Engine)
+	setBool(&sa.TypeScriptConfig.TypeScriptEnabled, fc.StaticAnalysis.TypeScript.Enabled)
+	setString(&sa.TypeScriptConfig.ESLintConfig, fc.StaticAnalysis.TypeScript.ESLintConfig)
+	setBool(&sa.TypeScriptConfig.TypeCheck, fc.StaticAnalysis.TypeScript.TypeCheck)
+	setBool(&sa.TypeScriptConfig.TypeCheckAllLines, fc.StaticAnalysis.TypeScript.TypeCheckAllLines)
+	setBool(&sa.TypeScriptConfig.TypeCheckScript, fc.StaticAnalysis.TypeScript.TypeCheckScript)
+	setBool(&sa.GenerateGithubActions, fc.StaticAnalysis.GenerateGithubActions)
+	setString(&sa.Checkout, fc.StaticAnalysis.Checkout)
+
+	for _, linter := range sa.GoConfig.EnabledLinters {
+		for _, disabled := range sa.GoConfig.DisabledLinters {
+			if linter == disabled {
+				problems = append(problems, fmt.Sprintf("static_analysis.go: linter %q is both enabled and disabled", linter))
+			}
+		}
+	}
+
+	return config, problems
+}
+
+func setString(dst *string, value *string) {
+	if value != nil {
+		*dst = *value
+	}
+}
+
+func setInt(dst *int, value *int) {
+	if value != nil {
+		*dst = *value
+	}
+}
+
+func setInt64(dst *int64, value *int64) {
+	if value != nil {
+		*dst = *value
+	}
+}
+
+func setFloat(dst *float64, value *float64) {
+	if value != nil {
+		*dst = *value
+	}
+}
+
+func setBool(dst *bool, value *bool) {
+	if value != nil {
+		*dst = *value
+	}
+}
+
+func setSeverity(dst *models.Severity, value *models.Severity) {
+	if value != nil {
+		*dst = *value
+	}
+}
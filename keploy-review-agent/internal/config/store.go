@@ -0,0 +1,113 @@
+package config
+
+import (
+	"context"
+	"log"
+	"os"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// Snapshot is an immutable, versioned configuration. Jobs keep the snapshot
+// they started with, so a reload never changes the settings of running jobs.
+type Snapshot struct {
+	Version  int
+	Config   *Config
+	LoadedAt time.Time
+}
+
+// Store holds the current configuration and swaps it atomically when a
+// reload produces a valid configuration.
+type Store struct {
+	path    string
+	current atomic.Pointer[Snapshot]
+
+	mu      sync.Mutex // serializes reloads
+	modTime time.Time
+}
+
+// NewStore returns a store serving cfg as version 1. path is the file the
+// configuration was loaded from, or empty when it came from the environment.
+func NewStore(path string, cfg *Config) *Store {
+	s := &Store{path: path}
+	s.current.Store(&Snapshot{Version: 1, Config: cfg, LoadedAt: time.Now()})
+	if path != "" {
+		if info, err := os.Stat(path); err == nil {
+			s.modTime = info.ModTime()
+		}
+	}
+	return s
+}
+
+func (s *Store) Current() *Snapshot {
+	return s.current.Load()
+}
+
+// Path returns the configuration file of the store, if any.
+func (s *Store) Path() string {
+	return s.path
+}
+
+// Reload loads and validates the configuration again. The current snapshot
+// is only replaced when the new configuration is valid.
+func (s *Store) Reload() (*Snapshot, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.path != "" {
+		if info, err := os.Stat(s.path); err == nil {
+			s.modTime = info.ModTime()
+		}
+	}
+
+	cfg, err := LoadFrom(s.path)
+	if err != nil {
+		return s.Current(), err
+	}
+
+	old := s.Current()
+	if cfg.ServerPort != old.Config.ServerPort {
+		log.Printf("Warning: server port changes from %s to %s only take effect after a restart",
+			old.Config.ServerPort, cfg.ServerPort)
+	}
+
+	snapshot := &Snapshot{Version: old.Version + 1, Config: cfg, LoadedAt: time.Now()}
+	s.current.Store(snapshot)
+	return snapshot, nil
+}
+
+// Watch polls the configuration file and reloads it when its modification
+// time changes, until ctx is done.
+func (s *Store) Watch(ctx context.Context, interval time.Duration) {
+	if s.path == "" {
+		return
+	}
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			info, err := os.Stat(s.path)
+			if err != nil {
+				continue
+			}
+			s.mu.Lock()
+			changed := !info.ModTime().Equal(s.modTime)
+			s.mu.Unlock()
+			if !changed {
+				continue
+			}
+
+			if snapshot, err := s.Reload(); err != nil {
+				log.Printf("Configuration reload failed, keeping version %d: %v", snapshot.Version, err)
+			} else {
+				log.Printf("Configuration reloaded from %s (version %d)", s.path, snapshot.Version)
+			}
+		}
+	}
+}
@@ -0,0 +1,90 @@
+package config
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"reflect"
+	"regexp"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+var (
+	yamlLineRegex         = regexp.MustCompile(`^(yaml: )?line \d+: `)
+	yamlUnknownFieldRegex = regexp.MustCompile(`^field (\S+) not found in type .*$`)
+)
+
+// DecodeYAML decodes a YAML mapping into the struct pointed to by out and
+// returns every problem found instead of stopping at the first one. Unknown
+// keys are rejected, and each top-level section is decoded on its own so a
+// bad value in one section does not hide problems in the others.
+func DecodeYAML(data []byte, out interface{}) []string {
+	var root yaml.Node
+	if err := yaml.Unmarshal(data, &root); err != nil {
+		return []string{err.Error()}
+	}
+	if len(root.Content) == 0 {
+		return nil
+	}
+
+	doc := root.Content[0]
+	if doc.Kind != yaml.MappingNode {
+		return []string{fmt.Sprintf("line %d: expected a mapping at the top level", doc.Line)}
+	}
+
+	v := reflect.ValueOf(out).Elem()
+	fields := make(map[string]reflect.Value)
+	for i := 0; i < v.NumField(); i++ {
+		name := strings.Split(v.Type().Field(i).Tag.Get("yaml"), ",")[0]
+		if name != "" && name != "-" {
+			fields[name] = v.Field(i)
+		}
+	}
+
+	var problems []string
+	for i := 0; i+1 < len(doc.Content); i += 2 {
+		key, value := doc.Content[i], doc.Content[i+1]
+		field, ok := fields[key.Value]
+		if !ok {
+			problems = append(problems, fmt.Sprintf("line %d: unknown key %q", key.Line, key.Value))
+			continue
+		}
+
+		section, err := yaml.Marshal(value)
+		if err != nil {
+			problems = append(problems, fmt.Sprintf("%s: %v", key.Value, err))
+			continue
+		}
+		dec := yaml.NewDecoder(bytes.NewReader(section))
+		dec.KnownFields(true)
+		if err := dec.Decode(field.Addr().Interface()); err != nil && !errors.Is(err, io.EOF) {
+			for _, msg := range yamlErrorMessages(err) {
+				problems = append(problems, fmt.Sprintf("%s (line %d): %s", key.Value, key.Line, msg))
+			}
+		}
+	}
+	return problems
+}
+
+func yamlErrorMessages(err error) []string {
+	var typeErr *yaml.TypeError
+	if !errors.As(err, &typeErr) {
+		return []string{cleanYAMLMessage(err.Error())}
+	}
+
+	var msgs []string
+	for _, msg := range typeErr.Errors {
+		msgs = append(msgs, cleanYAMLMessage(msg))
+	}
+	return msgs
+}
+
+// cleanYAMLMessage drops line numbers, which refer to the re-encoded section
+// rather than the original file, and Go type names from decoder errors.
+func cleanYAMLMessage(msg string) string {
+	msg = yamlLineRegex.ReplaceAllString(msg, "")
+	return yamlUnknownFieldRegex.ReplaceAllString(msg, `unknown field "$1"`)
+}
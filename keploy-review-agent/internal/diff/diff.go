@@ -0,0 +1,118 @@
+package diff
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+var hunkHeaderRegex = regexp.MustCompile(`^@@ -(\d+)(?:,(\d+))? \+(\d+)(?:,(\d+))? @@`)
+
+// Line kinds.
+const (
+	Context = ' '
+	Added   = '+'
+	Removed = '-'
+)
+
+// Line is a line of a hunk. NewLine is 0 for removed lines and OldLine is 0
+// for added lines.
+type Line struct {
+	Kind    byte
+	OldLine int
+	NewLine int
+	Text    string
+}
+
+// Hunk is a section of a unified diff.
+type Hunk struct {
+	OldStart, OldLines int
+	NewStart, NewLines int
+	Lines              []Line
+}
+
+// Contains reports whether line of the new file lies within the hunk.
+func (h *Hunk) Contains(line int) bool {
+	return h.NewLines > 0 && line >= h.NewStart && line < h.NewStart+h.NewLines
+}
+
+// Parse parses the hunks of a unified diff, such as the patch of a file
+// returned by the GitHub API. File headers are skipped.
+func Parse(patch string) ([]Hunk, error) {
+	var hunks []Hunk
+	var current *Hunk
+	var oldLine, newLine int
+
+	for i, text := range strings.Split(patch, "\n") {
+		if m := hunkHeaderRegex.FindStringSubmatch(text); m != nil {
+			hunks = append(hunks, Hunk{
+				OldStart: atoi(m[1]),
+				OldLines: count(m[2]),
+				NewStart: atoi(m[3]),
+				NewLines: count(m[4]),
+			})
+			current = &hunks[len(hunks)-1]
+			oldLine, newLine = current.OldStart, current.NewStart
+			continue
+		}
+		if current == nil || text == "" {
+			continue
+		}
+
+		switch text[0] {
+		case Added:
+			current.Lines = append(current.Lines, Line{Kind: Added, NewLine: newLine, Text: text[1:]})
+			newLine++
+		case Removed:
+			current.Lines = append(current.Lines, Line{Kind: Removed, OldLine: oldLine, Text: text[1:]})
+			oldLine++
+		case Context:
+			current.Lines = append(current.Lines, Line{Kind: Context, OldLine: oldLine, NewLine: newLine, Text: text[1:]})
+			oldLine++
+			newLine++
+		case '\\':
+			// "\ No newline at end of file"
+		default:
+			return nil, fmt.Errorf("line %d: unexpected diff line %q", i+1, text)
+		}
+	}
+
+	return hunks, nil
+}
+
+// InHunks reports whether line of the new file lies within one of the hunks.
+func InHunks(hunks []Hunk, line int) bool {
+	for i := range hunks {
+		if hunks[i].Contains(line) {
+			return true
+		}
+	}
+	return false
+}
+
+// AddedLines returns the new-file line numbers added by the hunks.
+func AddedLines(hunks []Hunk) map[int]bool {
+	added := make(map[int]bool)
+	for _, h := range hunks {
+		for _, l := range h.Lines {
+			if l.Kind == Added {
+				added[l.NewLine] = true
+			}
+		}
+	}
+	return added
+}
+
+func atoi(s string) int {
+	n, _ := strconv.Atoi(s)
+	return n
+}
+
+// count parses a hunk length, which defaults to 1 when omitted.
+func count(s string) int {
+	if s == "" {
+		return 1
+	}
+	return atoi(s)
+}
@@ -0,0 +1,136 @@
+package diff
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestParse(t *testing.T) {
+	tests := []struct {
+		name    string
+		patch   string
+		want    []Hunk
+		wantErr bool
+	}{
+		{
+			name:  "empty",
+			patch: "",
+		},
+		{
+			name:  "added and removed lines",
+			patch: "@@ -1,3 +1,3 @@\n a\n-b\n+c\n d",
+			want: []Hunk{{
+				OldStart: 1, OldLines: 3, NewStart: 1, NewLines: 3,
+				Lines: []Line{
+					{Kind: Context, OldLine: 1, NewLine: 1, Text: "a"},
+					{Kind: Removed, OldLine: 2, Text: "b"},
+					{Kind: Added, NewLine: 2, Text: "c"},
+					{Kind: Context, OldLine: 3, NewLine: 3, Text: "d"},
+				},
+			}},
+		},
+		{
+			name:  "omitted lengths default to one",
+			patch: "@@ -5 +7 @@ func f() {\n-x\n+y",
+			want: []Hunk{{
+				OldStart: 5, OldLines: 1, NewStart: 7, NewLines: 1,
+				Lines: []Line{
+					{Kind: Removed, OldLine: 5, Text: "x"},
+					{Kind: Added, NewLine: 7, Text: "y"},
+				},
+			}},
+		},
+		{
+			name:  "new file",
+			patch: "@@ -0,0 +1,2 @@\n+a\n+b\n\\ No newline at end of file",
+			want: []Hunk{{
+				OldStart: 0, OldLines: 0, NewStart: 1, NewLines: 2,
+				Lines: []Line{
+					{Kind: Added, NewLine: 1, Text: "a"},
+					{Kind: Added, NewLine: 2, Text: "b"},
+				},
+			}},
+		},
+		{
+			name:  "file headers are skipped",
+			patch: "diff --git a/f b/f\n--- a/f\n+++ b/f\n@@ -1 +1 @@\n-a\n+b",
+			want: []Hunk{{
+				OldStart: 1, OldLines: 1, NewStart: 1, NewLines: 1,
+				Lines: []Line{
+					{Kind: Removed, OldLine: 1, Text: "a"},
+					{Kind: Added, NewLine: 1, Text: "b"},
+				},
+			}},
+		},
+		{
+			name:  "several hunks",
+			patch: "@@ -1 +1,2 @@\n a\n+b\n@@ -10 +11 @@\n-c\n+d",
+			want: []Hunk{
+				{
+					OldStart: 1, OldLines: 1, NewStart: 1, NewLines: 2,
+					Lines: []Line{
+						{Kind: Context, OldLine: 1, NewLine: 1, Text: "a"},
+						{Kind: Added, NewLine: 2, Text: "b"},
+					},
+				},
+				{
+					OldStart: 10, OldLines: 1, NewStart: 11, NewLines: 1,
+					Lines: []Line{
+						{Kind: Removed, OldLine: 10, Text: "c"},
+						{Kind: Added, NewLine: 11, Text: "d"},
+					},
+				},
+			},
+		},
+		{
+			name:    "unexpected line",
+			patch:   "@@ -1 +1 @@\n?a",
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := Parse(tt.patch)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Parse() error = %v, wantErr %t", err, tt.wantErr)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Parse() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestAddedLines(t *testing.T) {
+	hunks, err := Parse("@@ -1,3 +1,4 @@\n a\n-b\n+c\n+d\n e\n@@ -20 +21 @@\n+f")
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := map[int]bool{2: true, 3: true, 21: true}
+	if got := AddedLines(hunks); !reflect.DeepEqual(got, want) {
+		t.Errorf("AddedLines() = %v, want %v", got, want)
+	}
+}
+
+func TestInHunks(t *testing.T) {
+	hunks, err := Parse("@@ -1,3 +4,3 @@\n a\n b\n c\n@@ -10,1 +20,0 @@\n-d")
+	if err != nil {
+		t.Fatal(err)
+	}
+	tests := []struct {
+		line int
+		want bool
+	}{
+		{3, false},
+		{4, true},
+		{6, true},
+		{7, false},
+		{20, false}, // a hunk removing lines covers no line of the new file
+	}
+	for _, tt := range tests {
+		if got := InHunks(hunks, tt.line); got != tt.want {
+			t.Errorf("InHunks(%d) = %t, want %t", tt.line, got, tt.want)
+		}
+	}
+}
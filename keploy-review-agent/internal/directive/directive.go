@@ -0,0 +1,239 @@
+package directive
+
+import (
+	"fmt"
+	"path"
+	"strings"
+
+	"github.com/keploy/keploy-review-agent/internal/diff"
+	"github.com/keploy/keploy-review-agent/pkg/models"
+)
+
+// Rules of the findings reported about directives themselves.
+const (
+	RuleUnused        = "keploy-review/unused-directive"
+	RuleMissingReason = "keploy-review/directive-missing-reason"
+)
+
+const (
+	ignorePrefix     = "keploy-review:ignore"
+	ignoreFilePrefix = "keploy-review:ignore-file"
+)
+
+// Directive is an inline suppression comment:
+//
+//	// keploy-review:ignore <rule>[,<rule>...] -- reason
+//	# keploy-review:ignore-file <rule> -- reason
+//
+// A line directive silences issues on its own line and on the line below
+// it; a file directive silences issues anywhere in the file. Without rules,
+// a directive silences every rule.
+type Directive struct {
+	Path   string
+	Line   int
+	File   bool
+	Rules  []string // rule IDs, or rule prefixes such as "ai" for "ai/security"
+	Reason string
+	used   bool
+}
+
+// commentPrefixes returns the line comment syntaxes of the language of a
+// file, or nil for languages without directive support.
+func commentPrefixes(filePath string) []string {
+	switch path.Ext(filePath) {
+	case ".go", ".js", ".jsx", ".mjs", ".cjs", ".ts", ".tsx":
+		return []string{"//", "/*"}
+	case ".py", ".yml", ".yaml", ".sh", ".bash", ".zsh":
+		return []string{"#"}
+	}
+	return nil
+}
+
+// Parse returns the directives of a file.
+func Parse(filePath, content string) []*Directive {
+	prefixes := commentPrefixes(filePath)
+	if len(prefixes) == 0 || !strings.Contains(content, ignorePrefix) {
+		return nil
+	}
+
+	var directives []*Directive
+	for i, line := range strings.Split(content, "\n") {
+		text, ok := commentText(line, prefixes)
+		if !ok {
+			continue
+		}
+		d := &Directive{Path: filePath, Line: i + 1}
+		switch {
+		case strings.HasPrefix(text, ignoreFilePrefix):
+			d.File = true
+			text = text[len(ignoreFilePrefix):]
+		case strings.HasPrefix(text, ignorePrefix):
+			text = text[len(ignorePrefix):]
+		default:
+			continue
+		}
+		if text != "" && text[0] != ' ' && text[0] != '\t' {
+			continue // another word, such as keploy-review:ignored
+		}
+
+		rules, reason := text, ""
+		if i := strings.Index(text, "--"); i >= 0 {
+			rules, reason = text[:i], strings.TrimSpace(text[i+2:])
+		}
+		d.Rules = strings.FieldsFunc(rules, func(r rune) bool { return r == ',' || r == ' ' || r == '\t' })
+		d.Reason = reason
+		directives = append(directives, d)
+	}
+	return directives
+}
+
+// commentText returns the text of the comment on a line, without the comment
+// syntax, when the comment starts with a directive. Directives quoted inside
+// another comment, as in documentation, are not directives.
+func commentText(line string, prefixes []string) (string, bool) {
+	for _, prefix := range prefixes {
+		rest := line
+		for {
+			i := strings.Index(rest, prefix)
+			if i < 0 {
+				break
+			}
+			text := strings.TrimSpace(rest[i+len(prefix):])
+			if strings.HasPrefix(text, ignorePrefix) {
+				if prefix == "/*" {
+					text = strings.TrimSpace(strings.TrimSuffix(text, "*/"))
+				}
+				return text, true
+			}
+			if strings.HasPrefix(text, prefix) {
+				break
+			}
+			rest = rest[i+len(prefix):]
+		}
+	}
+	return "", false
+}
+
+// matches reports whether the directive silences the issue.
+func (d *Directive) matches(issue *models.Issue) bool {
+	if !d.File && issue.Line != d.Line && issue.Line != d.Line+1 {
+		return false
+	}
+	return d.coversRule(issue.RuleID)
+}
+
+func (d *Directive) coversRule(rule string) bool {
+	if len(d.Rules) == 0 {
+		return true
+	}
+	for _, r := range d.Rules {
+		if r == rule || strings.HasPrefix(rule, r+"/") {
+			return true
+		}
+	}
+	return false
+}
+
+// Apply drops the issues silenced by the directives of the files, and then
+// those below minSeverity, and returns the remaining issues followed by
+// findings for directives without a reason and for unused directives. A
+// directive silencing only issues below minSeverity is still used.
+// Directives are only reported on lines added by the diff of their file, or
+// anywhere in files without a diff, so a change is not held responsible for
+// directives it did not touch. Directives covering a rule or rule prefix in
+// unverified, such as the rules of an analyzer that did not run, are never
+// reported as unused; "*" disables the unused check altogether.
+func Apply(files []*models.File, issues []*models.Issue, minSeverity models.Severity, unverified []string) []*models.Issue {
+	byPath := make(map[string][]*Directive)
+	for _, file := range files {
+		if directives := Parse(file.Path, file.Content); len(directives) > 0 {
+			byPath[file.Path] = directives
+		}
+	}
+	if len(byPath) == 0 {
+		return models.FilterBySeverity(issues, minSeverity)
+	}
+
+	var kept []*models.Issue
+	for _, issue := range issues {
+		silenced := false
+		for _, d := range byPath[issue.Path] {
+			if d.matches(issue) {
+				d.used = true
+				silenced = true
+			}
+		}
+		if !silenced {
+			kept = append(kept, issue)
+		}
+	}
+	kept = models.FilterBySeverity(kept, minSeverity)
+
+	for _, file := range files {
+		added := addedLines(file)
+		for _, d := range byPath[file.Path] {
+			if added != nil && !added[d.Line] {
+				continue
+			}
+			if d.Reason == "" {
+				kept = append(kept, d.finding(RuleMissingReason, models.SeverityWarning,
+					"Suppression directive without a reason",
+					"Explain why the finding is silenced after `--`, for example `keploy-review:ignore <rule> -- reason`."))
+			}
+			if !d.used && !d.unverified(unverified) {
+				kept = append(kept, d.finding(RuleUnused, models.SeverityInfo,
+					"Unused suppression directive",
+					fmt.Sprintf("No %s finding is silenced by this directive; remove it.", d.scope())))
+			}
+		}
+	}
+	return kept
+}
+
+// addedLines returns the lines added by the diff of a file, or nil when the
+// file has no diff or it cannot be parsed.
+func addedLines(file *models.File) map[int]bool {
+	if file.Patch == "" {
+		return nil
+	}
+	hunks, err := diff.Parse(file.Patch)
+	if err != nil {
+		return nil
+	}
+	return diff.AddedLines(hunks)
+}
+
+func (d *Directive) unverified(prefixes []string) bool {
+	for _, prefix := range prefixes {
+		if prefix == "*" || d.coversRule(prefix) {
+			return true
+		}
+		for _, r := range d.Rules {
+			if strings.HasPrefix(r, prefix+"/") {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+func (d *Directive) scope() string {
+	if len(d.Rules) == 0 {
+		return "current"
+	}
+	return "`" + strings.Join(d.Rules, "`, `") + "`"
+}
+
+func (d *Directive) finding(rule string, severity models.Severity, title, description string) *models.Issue {
+	return &models.Issue{
+		Path:        d.Path,
+		Line:        d.Line,
+		EndLine:     d.Line,
+		Severity:    severity,
+		RuleID:      rule,
+		Category:    "maintainability",
+		Title:       title,
+		Description: description,
+		Source:      "Suppression Directives",
+	}
+}
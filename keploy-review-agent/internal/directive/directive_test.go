@@ -0,0 +1,142 @@
+package directive
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/keploy/keploy-review-agent/pkg/models"
+)
+
+func TestParse(t *testing.T) {
+	tests := []struct {
+		name    string
+		path    string
+		content string
+		want    []*Directive
+	}{
+		{
+			name:    "line directive with rules and a reason",
+			path:    "main.go",
+			content: "package main\n\nx := 1 // keploy-review:ignore G104, errcheck -- checked by the caller",
+			want:    []*Directive{{Path: "main.go", Line: 3, Rules: []string{"G104", "errcheck"}, Reason: "checked by the caller"}},
+		},
+		{
+			name:    "file directive",
+			path:    "app.py",
+			content: "# keploy-review:ignore-file ai -- generated",
+			want:    []*Directive{{Path: "app.py", Line: 1, File: true, Rules: []string{"ai"}, Reason: "generated"}},
+		},
+		{
+			name:    "block comment without rules or reason",
+			path:    "app.ts",
+			content: "/* keploy-review:ignore */\nconsole.log(x)",
+			want:    []*Directive{{Path: "app.ts", Line: 1, Rules: []string{}}},
+		},
+		{
+			name:    "commented-out directive",
+			path:    "doc.go",
+			content: "// // keploy-review:ignore errcheck -- commented out",
+		},
+		{
+			name:    "another word",
+			path:    "main.go",
+			content: "// keploy-review:ignored on purpose",
+		},
+		{
+			name:    "unsupported language",
+			path:    "README.md",
+			content: "// keploy-review:ignore",
+		},
+		{
+			name:    "not in a comment",
+			path:    "main.go",
+			content: `s := "keploy-review:ignore"`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := Parse(tt.path, tt.content); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Parse() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestApply(t *testing.T) {
+	const content = "package main\n" +
+		"// keploy-review:ignore errcheck -- best effort\n" + // 2
+		"f()\n" +
+		"// keploy-review:ignore gosec\n" + // 4
+		"g()\n" +
+		"// keploy-review:ignore ai -- reviewed\n" + // 6
+		"h()\n"
+
+	issue := func(line int, rule string, severity models.Severity) *models.Issue {
+		return &models.Issue{Path: "main.go", Line: line, RuleID: rule, Severity: severity}
+	}
+	type result struct {
+		Line int
+		Rule string
+	}
+
+	tests := []struct {
+		name        string
+		patch       string
+		issues      []*models.Issue
+		minSeverity models.Severity
+		unverified  []string
+		want        []result
+	}{
+		{
+			name:   "silences the next line",
+			issues: []*models.Issue{issue(3, "errcheck", models.SeverityWarning), issue(5, "errcheck", models.SeverityWarning)},
+			want: []result{
+				{5, "errcheck"},
+				{4, RuleMissingReason},
+				{4, RuleUnused},
+				{6, RuleUnused},
+			},
+		},
+		{
+			name:   "rule prefixes",
+			issues: []*models.Issue{issue(7, "ai/security", models.SeverityError), issue(5, "gosec", models.SeverityError)},
+			want:   []result{{2, RuleUnused}, {4, RuleMissingReason}},
+		},
+		{
+			name:        "directives apply before the severity filter",
+			issues:      []*models.Issue{issue(3, "errcheck", models.SeverityHint), issue(5, "other", models.SeverityHint)},
+			minSeverity: models.SeverityWarning,
+			unverified:  []string{"ai"},
+			want:        []result{{4, RuleMissingReason}, {4, RuleUnused}},
+		},
+		{
+			name:       "unverified rules are not reported unused",
+			unverified: []string{"*"},
+			want:       []result{{4, RuleMissingReason}},
+		},
+		{
+			name:  "only directives on added lines are reported",
+			patch: "@@ -1,3 +1,5 @@\n package main\n+// keploy-review:ignore errcheck -- best effort\n f()\n+// keploy-review:ignore gosec\n g()",
+			want:  []result{{2, RuleUnused}, {4, RuleMissingReason}, {4, RuleUnused}},
+		},
+		{
+			name:   "directives on unchanged lines still silence",
+			patch:  "@@ -6 +6,2 @@\n // keploy-review:ignore ai -- reviewed\n+h()",
+			issues: []*models.Issue{issue(3, "errcheck", models.SeverityError)},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			files := []*models.File{{Path: "main.go", Content: content, Patch: tt.patch}}
+			var got []result
+			for _, issue := range Apply(files, tt.issues, tt.minSeverity, tt.unverified) {
+				got = append(got, result{issue.Line, issue.RuleID})
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Apply() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,120 @@
+package event
+
+import (
+	"encoding/json"
+	"fmt"
+	"log"
+	"strings"
+
+	"github.com/keploy/keploy-review-agent/internal/analyzer"
+	"github.com/keploy/keploy-review-agent/internal/command"
+	"github.com/keploy/keploy-review-agent/internal/config"
+)
+
+// commentPayload holds the fields shared by the issue_comment and
+// pull_request_review_comment webhook payloads.
+type commentPayload struct {
+	Action  string `json:"action"`
+	Comment struct {
+		ID          int64  `json:"id"`
+		Body        string `json:"body"`
+		InReplyToID int64  `json:"in_reply_to_id"`
+		User        struct {
+			Login string `json:"login"`
+			Type  string `json:"type"`
+		} `json:"user"`
+	} `json:"comment"`
+	Issue struct {
+		Number      int              `json:"number"`
+		PullRequest *json.RawMessage `json:"pull_request"` // set only for pull requests
+	} `json:"issue"`
+	PullRequest struct {
+		Number int `json:"number"`
+	} `json:"pull_request"`
+	Repository struct {
+		Name  string `json:"name"`
+		Owner struct {
+			Login string `json:"login"`
+		} `json:"owner"`
+	} `json:"repository"`
+}
+
+// processComment handles a comment posted on a pull request. Comments by
+// bots, including the agent's own replies, are ignored.
+func (p *Processor) processComment(eventType string, payload []byte) error {
+	var event commentPayload
+	if err := json.Unmarshal(payload, &event); err != nil {
+		return fmt.Errorf("failed to parse %s payload: %w", eventType, err)
+	}
+	if event.Action != "created" {
+		return nil
+	}
+
+	snapshot := p.store.Current()
+	if isBot(event.Comment.User.Login, event.Comment.User.Type, snapshot.Config.GitHubBotLogin) {
+		return nil
+	}
+
+	comment := &analyzer.CommentEvent{
+		RepoOwner: event.Repository.Owner.Login,
+		RepoName:  event.Repository.Name,
+		ID:        event.Comment.ID,
+		Author:    event.Comment.User.Login,
+		Body:      event.Comment.Body,
+	}
+	switch eventType {
+	case "issue_comment":
+		if event.Issue.PullRequest == nil {
+			return nil // a comment on an issue, not a pull request
+		}
+		comment.PRNumber = event.Issue.Number
+	case "pull_request_review_comment":
+		comment.PRNumber = event.PullRequest.Number
+		comment.InReplyTo = event.Comment.InReplyToID
+		comment.Review = true
+	}
+
+	orchestrator := p.orchestratorFor(snapshot)
+	if !comment.Review {
+		cmd, err := command.Parse(comment.Body)
+		if err != nil {
+			return orchestrator.ReportCommandError(comment, err)
+		}
+		if cmd != nil {
+			p.runCommand(snapshot, orchestrator, comment, cmd)
+			return nil
+		}
+	}
+
+	log.Printf("Handling %s by %s on %s/%s PR #%d", eventType, comment.Author, comment.RepoOwner, comment.RepoName, comment.PRNumber)
+	if err := orchestrator.HandleComment(comment); err != nil {
+		return fmt.Errorf("failed to handle comment: %w", err)
+	}
+	return nil
+}
+
+// runCommand queues a slash command as a job, so it shows up in the admin API
+// next to the reviews started by pull request events. Commands run one at a
+// time per pull request, and a command repeated while the same one is still
+// waiting runs only once.
+func (p *Processor) runCommand(snapshot *config.Snapshot, orchestrator *analyzer.Orchestrator, comment *analyzer.CommentEvent, cmd *command.Command) {
+	repo := comment.RepoOwner + "/" + comment.RepoName
+	key := fmt.Sprintf("%s#%d", repo, comment.PRNumber)
+	queued := p.commands.Submit(key, strings.ToLower(cmd.Text), func() {
+		jobID := p.jobs.Start(repo, comment.PRNumber, snapshot.Version, cmd.Text)
+		log.Printf("Running %q by %s on %s PR #%d", cmd.Text, comment.Author, repo, comment.PRNumber)
+
+		err := orchestrator.RunCommand(comment, cmd)
+		p.jobs.Finish(jobID, err)
+		if err != nil {
+			log.Printf("Failed to run %q on %s PR #%d: %v", cmd.Text, repo, comment.PRNumber, err)
+		}
+	})
+	if !queued {
+		log.Printf("Skipping %q by %s on %s PR #%d, the same command is already queued", cmd.Text, comment.Author, repo, comment.PRNumber)
+	}
+}
+
+func isBot(login, userType, botLogin string) bool {
+	return userType == "Bot" || strings.EqualFold(strings.TrimSuffix(login, "[bot]"), botLogin)
+}
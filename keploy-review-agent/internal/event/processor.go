@@ -8,24 +8,60 @@ import (
 	"os"
 	"strconv"
 	"strings"
+	"sync"
 
 	"github.com/keploy/keploy-review-agent/internal/analyzer"
+	"github.com/keploy/keploy-review-agent/internal/analyzer/llm"
 	"github.com/keploy/keploy-review-agent/internal/config"
+	"github.com/keploy/keploy-review-agent/internal/jobs"
 )
 
 type Processor struct {
-	cfg        *config.Config
-	orchestrator *analyzer.Orchestrator
+	store    *config.Store
+	jobs     *jobs.Registry
+	commands *jobs.Queue // slash commands, run one at a time per pull request
+
+	mu                  sync.Mutex
+	orchestrator        *analyzer.Orchestrator
+	orchestratorVersion int
+	limiter             *llm.Limiter // shared by the orchestrators of every configuration
 }
 
-func NewProcessor(cfg *config.Config) *Processor {
+func NewProcessor(store *config.Store, registry *jobs.Registry) *Processor {
 	return &Processor{
-		cfg:        cfg,
-		orchestrator: analyzer.NewOrchestrator(cfg),
+		store:    store,
+		jobs:     registry,
+		commands: jobs.NewQueue(),
 	}
 }
 
+// orchestratorFor returns the orchestrator built for a configuration
+// snapshot. A new one is created after every reload; jobs that already
+// started keep using the orchestrator, and so the configuration, they began with.
+// The LLM rate limiter outlives reloads and takes the limits of the new
+// configuration.
+func (p *Processor) orchestratorFor(snapshot *config.Snapshot) *analyzer.Orchestrator {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if p.orchestrator == nil || p.orchestratorVersion != snapshot.Version {
+		cfg := snapshot.Config
+		if p.limiter == nil {
+			p.limiter = llm.NewLimiter(cfg.LLMRequestsPerMinute, cfg.LLMTokensPerMinute)
+		} else {
+			p.limiter.SetRates(cfg.LLMRequestsPerMinute, cfg.LLMTokensPerMinute)
+		}
+		p.orchestrator = analyzer.NewOrchestratorWithLimiter(cfg, p.limiter)
+		p.orchestratorVersion = snapshot.Version
+	}
+	return p.orchestrator
+}
+
 func (p *Processor) ProcessGitHubEvent(eventType string, payload []byte) error {
+	switch eventType {
+	case "issue_comment", "pull_request_review_comment":
+		return p.processComment(eventType, payload)
+	}
 
 
 
@@ -78,13 +114,17 @@ func (p *Processor) ProcessGitHubEvent(eventType string, payload []byte) error {
 		PRNumber:    prNumber,
 	}
 
-	log.Printf("Starting analysis for %s/%s PR ", job.RepoOwner, job.RepoName)
-	if issues, err := p.orchestrator.AnalyzeCode(job); err != nil {
+	snapshot := p.store.Current()
+	jobID := p.jobs.Start(owner+"/"+repoName, prNumber, snapshot.Version, eventType)
+
+	log.Printf("Starting analysis for %s/%s PR with config version %d", job.RepoOwner, job.RepoName, snapshot.Version)
+	result, err := p.orchestratorFor(snapshot).AnalyzeCode(job)
+	p.jobs.Finish(jobID, err)
+	if err != nil {
 		return fmt.Errorf("failed to analyze code: %w", err)
-	} else {
-		log.Printf("Analysis result: %v", issues)
 	}
-	
+	log.Printf("Analysis result: %v (quality gate passed: %t)", result.Issues, result.Verdict.Passed)
+
 	return nil
 }
 
@@ -1,26 +1,34 @@
 package event
 
 import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
 	"io/ioutil"
 	"log"
 	"net/http"
 
 	"github.com/gin-gonic/gin"
 	"github.com/keploy/keploy-review-agent/internal/config"
+	"github.com/keploy/keploy-review-agent/internal/jobs"
 )
 
 type WebhookHandler struct {
-	cfg       *config.Config
+	store     *config.Store
 	processor *Processor
 }
 
-func NewWebhookHandler(cfg *config.Config) *WebhookHandler {
+func NewWebhookHandler(store *config.Store, registry *jobs.Registry) *WebhookHandler {
 	return &WebhookHandler{
-		cfg:       cfg,
-		processor: NewProcessor(cfg),
+		store:     store,
+		processor: NewProcessor(store, registry),
 	}
 }
 
+// HandleGitHub verifies the signature of a GitHub webhook against the
+// configured secret and processes the event in the background. Comment
+// events start commands and replies, so they are ignored when no secret is
+// configured to authenticate them.
 func (h *WebhookHandler) HandleGitHub(c *gin.Context) {
 
 	signature := c.GetHeader("X-Hub-Signature-256")
@@ -35,9 +43,23 @@ func (h *WebhookHandler) HandleGitHub(c *gin.Context) {
 		return
 	}
 
+	secret := h.store.Current().Config.GitHubWebhookSecret
+	if secret != "" && !validSignature(secret, body, signature) {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Invalid signature"})
+		return
+	}
+
 	eventType := c.GetHeader("X-GitHub-Event")
 
-	if eventType == "pull_request" {
+	switch eventType {
+	case "issue_comment", "pull_request_review_comment":
+		if secret == "" {
+			log.Printf("Warning: Ignoring %s event, which cannot be verified without a webhook secret", eventType)
+			c.JSON(http.StatusOK, gin.H{"status": "ignored"})
+			return
+		}
+		fallthrough
+	case "pull_request":
 		go func() {
 			log.Printf("webhook file mein hoon ")
 			if err := h.processor.ProcessGitHubEvent(eventType, body); err != nil {
@@ -49,6 +71,15 @@ func (h *WebhookHandler) HandleGitHub(c *gin.Context) {
 	c.JSON(http.StatusOK, gin.H{"status": "processing"})
 }
 
+// validSignature reports whether signature, the X-Hub-Signature-256 header,
+// is the HMAC-SHA256 of body with secret.
+func validSignature(secret string, body []byte, signature string) bool {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write(body)
+	expected := "sha256=" + hex.EncodeToString(mac.Sum(nil))
+	return hmac.Equal([]byte(signature), []byte(expected))
+}
+
 func (h *WebhookHandler) HandleGitLab(c *gin.Context) {
 
 	body, err := ioutil.ReadAll(c.Request.Body)
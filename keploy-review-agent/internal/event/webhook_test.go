@@ -0,0 +1,30 @@
+package event
+
+import "testing"
+
+func TestValidSignature(t *testing.T) {
+	// The example of the GitHub webhook documentation.
+	const (
+		secret    = "It's a Secret to Everybody"
+		body      = "Hello, World!"
+		signature = "sha256=757107ea0eb2509fc211221cce984b8a37570b6d7586c22c46f4379c8b043e17"
+	)
+	tests := []struct {
+		name      string
+		secret    string
+		body      string
+		signature string
+		want      bool
+	}{
+		{"valid", secret, body, signature, true},
+		{"other body", secret, "Hello, World?", signature, false},
+		{"other secret", "guess", body, signature, false},
+		{"missing prefix", secret, body, signature[len("sha256="):], false},
+		{"empty", secret, body, "", false},
+	}
+	for _, tt := range tests {
+		if got := validSignature(tt.secret, []byte(tt.body), tt.signature); got != tt.want {
+			t.Errorf("%s: validSignature() = %t, want %t", tt.name, got, tt.want)
+		}
+	}
+}
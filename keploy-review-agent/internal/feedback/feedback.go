@@ -0,0 +1,292 @@
+package feedback
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Outcomes of a reported finding.
+const (
+	OutcomeOpen      = "open"
+	OutcomeAccepted  = "accepted"  // thumbs-up, or resolved after a fix
+	OutcomeDismissed = "dismissed" // thumbs-down, dismissed in its thread, or resolved without a fix
+)
+
+// Actions taken on rules with a high dismissal rate.
+const (
+	ActionDemote = "demote" // report with a lower severity
+	ActionMute   = "mute"   // do not report
+)
+
+// Signals setting the outcome of a finding, from the weakest to the
+// strongest. When signals conflict, the strongest one decides the outcome,
+// whatever the order they are seen in.
+const (
+	SignalThumbsUp   = "thumbs-up"
+	SignalFixed      = "resolved after a fix"
+	SignalResolved   = "resolved without a fix"
+	SignalThumbsDown = "thumbs-down"
+	SignalDismissed  = "dismissed in thread"
+)
+
+var signalPrecedence = map[string]int{
+	SignalThumbsUp:   1,
+	SignalFixed:      2,
+	SignalResolved:   3,
+	SignalThumbsDown: 4,
+	SignalDismissed:  5,
+}
+
+// SignalOutcome returns the outcome a signal gives a finding.
+func SignalOutcome(signal string) string {
+	switch signal {
+	case SignalThumbsUp, SignalFixed:
+		return OutcomeAccepted
+	case SignalResolved, SignalThumbsDown, SignalDismissed:
+		return OutcomeDismissed
+	}
+	return OutcomeOpen
+}
+
+// Stronger reports whether signal takes precedence over other.
+func Stronger(signal, other string) bool {
+	return signalPrecedence[signal] > signalPrecedence[other]
+}
+
+// Finding is the feedback recorded for one reported issue.
+type Finding struct {
+	Rule    string    `json:"rule"`
+	Outcome string    `json:"outcome"`
+	Reason  string    `json:"reason,omitempty"` // the signal that set the outcome
+	Updated time.Time `json:"updated"`
+}
+
+// Repo is the feedback of a repository, keyed by issue fingerprint.
+type Repo struct {
+	Findings map[string]*Finding `json:"findings"`
+}
+
+// Record sets the outcome of a finding from a signal. Open findings are only
+// added, never used to reopen a finding that already has an outcome. A
+// signal never overrides a stronger one, and a signal leaving the outcome
+// as it is does not touch the finding, so the signals seen again in every
+// review do not keep the finding from aging out of the window.
+func (r *Repo) Record(fingerprint, rule, outcome, reason string) {
+	if fingerprint == "" || rule == "" {
+		return
+	}
+	f, ok := r.Findings[fingerprint]
+	if !ok {
+		f = &Finding{Rule: rule, Outcome: OutcomeOpen, Updated: time.Now()}
+		r.Findings[fingerprint] = f
+	}
+	if outcome == OutcomeOpen || f.Outcome == outcome {
+		return
+	}
+	if f.Outcome != OutcomeOpen && Stronger(f.Reason, reason) {
+		return
+	}
+	f.Outcome, f.Reason, f.Updated = outcome, reason, time.Now()
+}
+
+// Prune removes the findings last updated before since, which no longer
+// count toward the statistics. A zero since keeps every finding.
+func (r *Repo) Prune(since time.Time) {
+	if since.IsZero() {
+		return
+	}
+	for fingerprint, f := range r.Findings {
+		if f.Updated.Before(since) {
+			delete(r.Findings, fingerprint)
+		}
+	}
+}
+
+// RuleStats are the false-positive statistics of a rule. LLM findings use
+// their category as rule, such as ai/security.
+type RuleStats struct {
+	Rule          string  `json:"rule"`
+	Reported      int     `json:"reported"`
+	Accepted      int     `json:"accepted"`
+	Dismissed     int     `json:"dismissed"`
+	DismissalRate float64 `json:"dismissal_rate"` // dismissed out of reported
+}
+
+// Stats returns the statistics of every rule, sorted by rule, counting the
+// findings updated since the given time.
+func (r *Repo) Stats(since time.Time) []RuleStats {
+	byRule := make(map[string]*RuleStats)
+	for _, f := range r.Findings {
+		if f.Updated.Before(since) {
+			continue
+		}
+		s, ok := byRule[f.Rule]
+		if !ok {
+			s = &RuleStats{Rule: f.Rule}
+			byRule[f.Rule] = s
+		}
+		s.Reported++
+		switch f.Outcome {
+		case OutcomeAccepted:
+			s.Accepted++
+		case OutcomeDismissed:
+			s.Dismissed++
+		}
+	}
+
+	stats := make([]RuleStats, 0, len(byRule))
+	for _, s := range byRule {
+		s.DismissalRate = float64(s.Dismissed) / float64(s.Reported)
+		stats = append(stats, *s)
+	}
+	sort.Slice(stats, func(i, j int) bool { return stats[i].Rule < stats[j].Rule })
+	return stats
+}
+
+// Policy decides when rules are demoted or muted. Only the feedback of the
+// last WindowDays counts, so a rule is judged again once its old dismissals
+// expire: a muted rule is reported again and earns new feedback.
+type Policy struct {
+	MinSamples int     `json:"min_samples"` // reported findings needed before a rule is judged
+	DemoteRate float64 `json:"demote_rate"` // dismissal rate from which a rule is demoted
+	MuteRate   float64 `json:"mute_rate"`   // dismissal rate from which a rule is muted
+	WindowDays int     `json:"window_days"` // days of feedback taken into account; 0 keeps all
+}
+
+// Since returns the time from which feedback counts at now.
+func (p Policy) Since(now time.Time) time.Time {
+	if p.WindowDays <= 0 {
+		return time.Time{}
+	}
+	return now.AddDate(0, 0, -p.WindowDays)
+}
+
+// Decision is the action taken on a rule and the statistics behind it.
+type Decision struct {
+	Action string    `json:"action"`
+	Stats  RuleStats `json:"stats"`
+}
+
+func (d Decision) String() string {
+	verb := "Demoted"
+	if d.Action == ActionMute {
+		verb = "Muted"
+	}
+	return fmt.Sprintf("%s `%s`: %d of %d findings dismissed (%.0f%%)",
+		verb, d.Stats.Rule, d.Stats.Dismissed, d.Stats.Reported, d.Stats.DismissalRate*100)
+}
+
+// Decide returns the action to take on each rule with enough samples and a
+// dismissal rate above the thresholds.
+func (p Policy) Decide(stats []RuleStats) map[string]Decision {
+	decisions := make(map[string]Decision)
+	for _, s := range stats {
+		if s.Reported < p.MinSamples {
+			continue
+		}
+		switch {
+		case s.DismissalRate >= p.MuteRate:
+			decisions[s.Rule] = Decision{Action: ActionMute, Stats: s}
+		case s.DismissalRate >= p.DemoteRate:
+			decisions[s.Rule] = Decision{Action: ActionDemote, Stats: s}
+		}
+	}
+	return decisions
+}
+
+// Store keeps the feedback of every repository on local disk, one JSON file
+// per repository.
+type Store struct {
+	dir string
+	mu  sync.Mutex // serializes updates, which read and rewrite a whole file
+}
+
+var (
+	openMu sync.Mutex
+	opened = make(map[string]*Store)
+)
+
+// Open returns the store kept in dir, creating the directory if needed.
+// Every caller opening the same directory shares one store, so concurrent
+// reviews do not overwrite each other's updates.
+func Open(dir string) (*Store, error) {
+	openMu.Lock()
+	defer openMu.Unlock()
+
+	dir = filepath.Clean(dir)
+	if s, ok := opened[dir]; ok {
+		return s, nil
+	}
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return nil, fmt.Errorf("failed to create feedback directory: %w", err)
+	}
+	s := &Store{dir: dir}
+	opened[dir] = s
+	return s, nil
+}
+
+// Load returns the feedback of a repository, given as owner/name.
+func (s *Store) Load(repo string) (*Repo, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.load(repo)
+}
+
+// Update applies fn to the feedback of a repository and saves the result.
+func (s *Store) Update(repo string, fn func(*Repo)) (*Repo, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	r, err := s.load(repo)
+	if err != nil {
+		return nil, err
+	}
+	fn(r)
+
+	data, err := json.MarshalIndent(r, "", "  ")
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode feedback: %w", err)
+	}
+	path := s.path(repo)
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return nil, fmt.Errorf("failed to create feedback directory: %w", err)
+	}
+	tmp := path + ".tmp"
+	if err := os.WriteFile(tmp, data, 0o644); err != nil {
+		return nil, fmt.Errorf("failed to write feedback: %w", err)
+	}
+	if err := os.Rename(tmp, path); err != nil {
+		os.Remove(tmp)
+		return nil, fmt.Errorf("failed to write feedback: %w", err)
+	}
+	return r, nil
+}
+
+func (s *Store) load(repo string) (*Repo, error) {
+	r := &Repo{Findings: make(map[string]*Finding)}
+	data, err := os.ReadFile(s.path(repo))
+	if os.IsNotExist(err) {
+		return r, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read feedback: %w", err)
+	}
+	if err := json.Unmarshal(data, r); err != nil {
+		return nil, fmt.Errorf("failed to decode feedback of %s: %w", repo, err)
+	}
+	if r.Findings == nil {
+		r.Findings = make(map[string]*Finding)
+	}
+	return r, nil
+}
+
+func (s *Store) path(repo string) string {
+	name := strings.NewReplacer("/", "__", "\\", "_", "..", "_").Replace(strings.ToLower(repo))
+	return filepath.Join(s.dir, name+".json")
+}
@@ -0,0 +1,67 @@
+package feedback
+
+import (
+	"testing"
+	"time"
+)
+
+func TestRecord(t *testing.T) {
+	tests := []struct {
+		name        string
+		signals     []string
+		wantOutcome string
+		wantReason  string
+	}{
+		{"single signal", []string{SignalThumbsUp}, OutcomeAccepted, SignalThumbsUp},
+		{"stronger signal wins", []string{SignalThumbsUp, SignalThumbsDown}, OutcomeDismissed, SignalThumbsDown},
+		{"weaker signal is ignored", []string{SignalDismissed, SignalFixed}, OutcomeDismissed, SignalDismissed},
+		{"same outcome keeps the first signal", []string{SignalResolved, SignalThumbsDown}, OutcomeDismissed, SignalResolved},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := &Repo{Findings: make(map[string]*Finding)}
+			r.Record("fp", "rule", OutcomeOpen, "")
+			for _, signal := range tt.signals {
+				r.Record("fp", "rule", SignalOutcome(signal), signal)
+			}
+			f := r.Findings["fp"]
+			if f.Outcome != tt.wantOutcome || f.Reason != tt.wantReason {
+				t.Errorf("Record() = %s (%s), want %s (%s)", f.Outcome, f.Reason, tt.wantOutcome, tt.wantReason)
+			}
+		})
+	}
+}
+
+func TestRecordRepeatedSignals(t *testing.T) {
+	r := &Repo{Findings: make(map[string]*Finding)}
+	r.Record("fp", "rule", OutcomeAccepted, SignalThumbsUp)
+	r.Record("fp", "rule", OutcomeDismissed, SignalThumbsDown)
+	updated := time.Now().AddDate(0, 0, -10)
+	r.Findings["fp"].Updated = updated
+
+	// Every later review sees both signals again.
+	for i := 0; i < 3; i++ {
+		r.Record("fp", "rule", OutcomeAccepted, SignalThumbsUp)
+		r.Record("fp", "rule", OutcomeDismissed, SignalThumbsDown)
+	}
+	if f := r.Findings["fp"]; !f.Updated.Equal(updated) || f.Outcome != OutcomeDismissed {
+		t.Errorf("repeated signals changed the finding to %s, updated %s", f.Outcome, f.Updated)
+	}
+}
+
+func TestPrune(t *testing.T) {
+	now := time.Now()
+	r := &Repo{Findings: map[string]*Finding{
+		"old":    {Rule: "rule", Outcome: OutcomeDismissed, Updated: now.AddDate(0, 0, -40)},
+		"recent": {Rule: "rule", Outcome: OutcomeDismissed, Updated: now.AddDate(0, 0, -5)},
+	}}
+
+	r.Prune(time.Time{})
+	if len(r.Findings) != 2 {
+		t.Fatalf("Prune() without a window removed findings")
+	}
+	r.Prune(Policy{WindowDays: 30}.Since(now))
+	if _, ok := r.Findings["old"]; ok || len(r.Findings) != 1 {
+		t.Errorf("Prune() kept %v, want only the recent finding", r.Findings)
+	}
+}
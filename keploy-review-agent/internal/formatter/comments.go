@@ -2,12 +2,23 @@ package formatter
 
 import (
 	"fmt"
+	"regexp"
+	"strings"
+
 	"github.com/keploy/keploy-review-agent/pkg/models"
 )
 
+var (
+	fingerprintRegex = regexp.MustCompile(`<!-- keploy-review:fingerprint=([0-9a-f]+) -->`)
+	suppressionRegex = regexp.MustCompile(`<!-- keploy-review:suppressed=([0-9a-f]+) -->`)
+	ignoredRuleRegex = regexp.MustCompile(`<!-- keploy-review:ignore-rule=(\S+) -->`)
+)
+
 func FormatLinterIssue(issue *models.Issue) *models.ReviewComment {
 	var emoji string
 	switch issue.Severity {
+	case models.SeverityCritical:
+		emoji = "🛑"
 	case models.SeverityError:
 		emoji = "🚨"
 	case models.SeverityWarning:
@@ -17,13 +28,113 @@ func FormatLinterIssue(issue *models.Issue) *models.ReviewComment {
 	}
 
 	body := fmt.Sprintf("%s **%s**\n\n%s", emoji, issue.Title, issue.Description)
+	if meta := formatMetadata(issue); meta != "" {
+		body += "\n\n" + meta
+	}
 	if issue.Suggestion != "" {
 		body += "\n\n**Suggestion:** " + issue.Suggestion
 	}
+	for _, fix := range issue.Fixes {
+		body += "\n\n" + formatFix(fix)
+	}
+	if len(issue.Sources) > 1 {
+		body += "\n\n_Reported by: " + strings.Join(issue.Sources, ", ") + "_"
+	}
+	if issue.Fingerprint != "" {
+		body += "\n\n" + FingerprintMarker(issue.Fingerprint)
+	}
 
 	return &models.ReviewComment{
-		Path:     issue.Path,
-		Line:     issue.Line,
-		Body:     body,
+		Path:        issue.Path,
+		Line:        issue.Line,
+		Body:        body,
+		Fingerprint: issue.Fingerprint,
+	}
+}
+
+func formatMetadata(issue *models.Issue) string {
+	var parts []string
+	if issue.RuleID != "" {
+		rule := "`" + issue.RuleID + "`"
+		if issue.DocURL != "" {
+			rule = fmt.Sprintf("[%s](%s)", rule, issue.DocURL)
+		}
+		parts = append(parts, "Rule: "+rule)
+	} else if issue.DocURL != "" {
+		parts = append(parts, fmt.Sprintf("[Documentation](%s)", issue.DocURL))
+	}
+	if issue.Category != "" {
+		parts = append(parts, "Category: "+issue.Category)
+	}
+	if issue.Confidence > 0 {
+		parts = append(parts, fmt.Sprintf("Confidence: %.0f%%", issue.Confidence*100))
+	}
+	if len(issue.Tags) > 0 {
+		parts = append(parts, "Tags: "+strings.Join(issue.Tags, ", "))
+	}
+	return strings.Join(parts, " · ")
+}
+
+func formatFix(fix models.Fix) string {
+	title := "**Fix:**"
+	if fix.Description != "" {
+		title = fmt.Sprintf("**Fix:** %s", fix.Description)
+	}
+
+	var b strings.Builder
+	b.WriteString(title)
+	for _, edit := range fix.Edits {
+		if edit.NewText == "" {
+			fmt.Fprintf(&b, "\n- remove %d:%d-%d:%d", edit.StartLine, edit.StartColumn, edit.EndLine, edit.EndColumn)
+			continue
+		}
+		fmt.Fprintf(&b, "\n- replace %d:%d-%d:%d with:\n```\n%s\n```",
+			edit.StartLine, edit.StartColumn, edit.EndLine, edit.EndColumn, edit.NewText)
+	}
+	return b.String()
+}
+
+// FingerprintMarker returns the hidden marker embedded in posted comments so
+// that later runs can recognise issues that were already reported.
+func FingerprintMarker(fingerprint string) string {
+	return fmt.Sprintf("<!-- keploy-review:fingerprint=%s -->", fingerprint)
+}
+
+// ExtractFingerprints returns all fingerprint markers found in a comment body.
+func ExtractFingerprints(body string) []string {
+	var fingerprints []string
+	for _, m := range fingerprintRegex.FindAllStringSubmatch(body, -1) {
+		fingerprints = append(fingerprints, m[1])
+	}
+	return fingerprints
+}
+
+// SuppressionMarker returns the hidden marker recording that a developer
+// dismissed the issue with the given fingerprint.
+func SuppressionMarker(fingerprint string) string {
+	return fmt.Sprintf("<!-- keploy-review:suppressed=%s -->", fingerprint)
+}
+
+// ExtractSuppressions returns all suppression markers found in a comment body.
+func ExtractSuppressions(body string) []string {
+	var fingerprints []string
+	for _, m := range suppressionRegex.FindAllStringSubmatch(body, -1) {
+		fingerprints = append(fingerprints, m[1])
+	}
+	return fingerprints
+}
+
+// IgnoredRuleMarker returns the hidden marker recording that a rule is
+// ignored for the rest of a pull request.
+func IgnoredRuleMarker(rule string) string {
+	return fmt.Sprintf("<!-- keploy-review:ignore-rule=%s -->", rule)
+}
+
+// ExtractIgnoredRules returns all ignored rule markers found in a comment body.
+func ExtractIgnoredRules(body string) []string {
+	var rules []string
+	for _, m := range ignoredRuleRegex.FindAllStringSubmatch(body, -1) {
+		rules = append(rules, m[1])
 	}
+	return rules
 }
@@ -0,0 +1,77 @@
+package formatter
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/keploy/keploy-review-agent/pkg/models"
+)
+
+// Markers delimiting the walkthrough in sticky comments and PR descriptions.
+const (
+	WalkthroughMarker = "<!-- keploy-review:walkthrough -->"
+	walkthroughStart  = "<!-- keploy-review:walkthrough:start -->"
+	walkthroughEnd    = "<!-- keploy-review:walkthrough:end -->"
+)
+
+// FormatWalkthrough renders a pull request walkthrough as Markdown.
+func FormatWalkthrough(w *models.Walkthrough) string {
+	var b strings.Builder
+
+	b.WriteString("## 🧭 Walkthrough\n\n")
+	b.WriteString(strings.TrimSpace(w.Overview) + "\n")
+
+	if len(w.Files) > 0 {
+		b.WriteString("\n### Changes\n\n| File | Summary |\n|------|---------|\n")
+		for _, f := range w.Files {
+			fmt.Fprintf(&b, "| `%s` | %s |\n", f.Path, tableCell(f.Summary))
+		}
+	}
+	writeList(&b, "⚠️ Risk areas", w.Risks)
+	writeList(&b, "🧪 Suggested test focus", w.TestFocus)
+
+	return b.String()
+}
+
+// ReplaceWalkthroughSection returns the PR description with its walkthrough
+// section replaced by walkthrough, appending the section when missing.
+func ReplaceWalkthroughSection(description, walkthrough string) string {
+	section := walkthroughStart + "\n" + walkthrough + "\n" + walkthroughEnd
+
+	start := strings.Index(description, walkthroughStart)
+	end := strings.Index(description, walkthroughEnd)
+	if start >= 0 && end > start {
+		return description[:start] + section + description[end+len(walkthroughEnd):]
+	}
+
+	if strings.TrimSpace(description) == "" {
+		return section
+	}
+	return strings.TrimRight(description, "\n") + "\n\n" + section
+}
+
+// StripWalkthroughSection removes the walkthrough section from a PR
+// description, so earlier walkthroughs are not summarized again.
+func StripWalkthroughSection(description string) string {
+	start := strings.Index(description, walkthroughStart)
+	end := strings.Index(description, walkthroughEnd)
+	if start < 0 || end < start {
+		return description
+	}
+	return strings.TrimSpace(description[:start] + description[end+len(walkthroughEnd):])
+}
+
+func writeList(b *strings.Builder, title string, items []string) {
+	if len(items) == 0 {
+		return
+	}
+	fmt.Fprintf(b, "\n### %s\n\n", title)
+	for _, item := range items {
+		fmt.Fprintf(b, "- %s\n", strings.TrimSpace(item))
+	}
+}
+
+func tableCell(text string) string {
+	text = strings.ReplaceAll(strings.TrimSpace(text), "\n", " ")
+	return strings.ReplaceAll(text, "|", `\|`)
+}
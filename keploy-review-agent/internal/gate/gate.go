@@ -0,0 +1,191 @@
+package gate
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+
+	"gopkg.in/yaml.v3"
+
+	"github.com/keploy/keploy-review-agent/internal/glob"
+	"github.com/keploy/keploy-review-agent/pkg/models"
+)
+
+// Rule holds the thresholds of a quality gate. Unset fields impose no limit.
+type Rule struct {
+	FailOn      *models.Severity `yaml:"fail_on"`
+	MaxCritical *int             `yaml:"max_critical"`
+	MaxErrors   *int             `yaml:"max_errors"`
+	MaxWarnings *int             `yaml:"max_warnings"`
+	FailOnCVSS  *float64         `yaml:"fail_on_cvss"` // counts only dependencies added by the change
+}
+
+// PathRule applies a rule to the issues of files matching a glob. Fields left
+// unset are inherited from the enclosing gate.
+type PathRule struct {
+	Path string `yaml:"path"`
+	Rule `yaml:",inline"`
+}
+
+// Config is the gate of a repository: a default rule plus path overrides.
+// The first path rule matching an issue's file decides the severity and CVSS
+// thresholds of the issue and the path limits it counts against. Every issue
+// also counts against the limits of the default rule, so path rules cannot
+// raise the total allowed for the repository.
+type Config struct {
+	Rule  `yaml:",inline"`
+	Paths []PathRule `yaml:"paths"`
+}
+
+// File is the server-side gate definition with per-repository overrides
+// keyed by "owner/repo".
+type File struct {
+	Default Config            `yaml:"default"`
+	Repos   map[string]Config `yaml:"repos"`
+}
+
+type Verdict struct {
+	Passed  bool
+	Reasons []string
+}
+
+func LoadFile(path string) (*File, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read quality gate file: %w", err)
+	}
+
+	var file File
+	dec := yaml.NewDecoder(bytes.NewReader(data))
+	dec.KnownFields(true)
+	if err := dec.Decode(&file); err != nil && !errors.Is(err, io.EOF) {
+		return nil, fmt.Errorf("failed to parse quality gate file %s: %w", path, err)
+	}
+	return &file, nil
+}
+
+// ForRepo returns the gate of a repository, with the repository's settings
+// layered over the file's defaults.
+func (f *File) ForRepo(owner, repo string) Config {
+	cfg := f.Default
+	if override, ok := f.Repos[owner+"/"+repo]; ok {
+		cfg = cfg.Merge(override)
+	}
+	return cfg
+}
+
+// DefaultConfig is the gate used when none is configured: it only fails on
+// issues at or above the given severity.
+func DefaultConfig(failOn models.Severity) Config {
+	return Config{Rule: Rule{FailOn: &failOn}}
+}
+
+// Merge returns c with the fields set in override replacing its own. Path
+// rules of the override take precedence over the existing ones.
+func (c Config) Merge(override Config) Config {
+	return Config{
+		Rule:  c.Rule.merge(override.Rule),
+		Paths: append(append([]PathRule{}, override.Paths...), c.Paths...),
+	}
+}
+
+func (r Rule) merge(override Rule) Rule {
+	if override.FailOn != nil {
+		r.FailOn = override.FailOn
+	}
+	if override.MaxCritical != nil {
+		r.MaxCritical = override.MaxCritical
+	}
+	if override.MaxErrors != nil {
+		r.MaxErrors = override.MaxErrors
+	}
+	if override.MaxWarnings != nil {
+		r.MaxWarnings = override.MaxWarnings
+	}
+	if override.FailOnCVSS != nil {
+		r.FailOnCVSS = override.FailOnCVSS
+	}
+	return r
+}
+
+// Evaluate checks the issues of a review against the gate.
+func (c Config) Evaluate(issues []*models.Issue) *Verdict {
+	scopes := make(map[string][]*models.Issue)
+	rules := map[string]Rule{"": c.Rule}
+	order := []string{""}
+
+	for _, issue := range issues {
+		scope := ""
+		for _, pr := range c.Paths {
+			if glob.Match(pr.Path, issue.Path) {
+				scope = pr.Path
+				if _, ok := rules[scope]; !ok {
+					rules[scope] = c.Rule.merge(pr.Rule)
+					order = append(order, scope)
+				}
+				break
+			}
+		}
+		scopes[scope] = append(scopes[scope], issue)
+	}
+
+	verdict := &Verdict{Passed: true}
+	for _, scope := range order {
+		counted := scopes[scope]
+		if scope == "" {
+			counted = issues
+		}
+		for _, reason := range rules[scope].check(scopes[scope], counted) {
+			if scope != "" {
+				reason = fmt.Sprintf("%s: %s", scope, reason)
+			}
+			verdict.Reasons = append(verdict.Reasons, reason)
+		}
+	}
+	verdict.Passed = len(verdict.Reasons) == 0
+	return verdict
+}
+
+// check returns the reasons the issues fail the rule, counting the issues
+// in counted against its limits.
+func (r Rule) check(issues, counted []*models.Issue) []string {
+	var reasons []string
+	counts := make(map[models.Severity]int)
+	for _, issue := range counted {
+		counts[issue.Severity]++
+	}
+
+	if r.FailOn != nil {
+		if failing := len(models.FilterBySeverity(issues, *r.FailOn)); failing > 0 {
+			reasons = append(reasons, fmt.Sprintf("%d issues at or above %s", failing, *r.FailOn))
+		}
+	}
+
+	limits := []struct {
+		max      *int
+		severity models.Severity
+	}{
+		{r.MaxCritical, models.SeverityCritical},
+		{r.MaxErrors, models.SeverityError},
+		{r.MaxWarnings, models.SeverityWarning},
+	}
+	for _, limit := range limits {
+		if limit.max != nil && counts[limit.severity] > *limit.max {
+			reasons = append(reasons, fmt.Sprintf("%d %s issues (maximum %d)",
+				counts[limit.severity], limit.severity, *limit.max))
+		}
+	}
+
+	if r.FailOnCVSS != nil {
+		for _, issue := range issues {
+			if issue.NewDep && issue.CVSS > 0 && issue.CVSS >= *r.FailOnCVSS {
+				reasons = append(reasons, fmt.Sprintf("vulnerable dependency in %s: %s (CVSS %.1f, limit %.1f)",
+					issue.Path, issue.Title, issue.CVSS, *r.FailOnCVSS))
+			}
+		}
+	}
+
+	return reasons
+}
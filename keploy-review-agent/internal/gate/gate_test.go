@@ -0,0 +1,97 @@
+package gate
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"github.com/keploy/keploy-review-agent/pkg/models"
+)
+
+func TestEvaluate(t *testing.T) {
+	limit := func(n int) *int { return &n }
+	severity := func(s models.Severity) *models.Severity { return &s }
+	issues := func(path string, n int, severity models.Severity) []*models.Issue {
+		var issues []*models.Issue
+		for i := 0; i < n; i++ {
+			issues = append(issues, &models.Issue{Path: path, Severity: severity})
+		}
+		return issues
+	}
+
+	tests := []struct {
+		name   string
+		config Config
+		issues []*models.Issue
+		want   []string
+	}{
+		{
+			name:   "within the limits",
+			config: Config{Rule: Rule{MaxErrors: limit(5)}},
+			issues: issues("a.go", 5, models.SeverityError),
+		},
+		{
+			name: "path issues count toward the repository limits",
+			config: Config{
+				Rule:  Rule{MaxErrors: limit(5)},
+				Paths: []PathRule{{Path: "legacy/**", Rule: Rule{MaxErrors: limit(10)}}},
+			},
+			issues: append(issues("legacy/a.go", 4, models.SeverityError), issues("b.go", 4, models.SeverityError)...),
+			want:   []string{"8 error issues (maximum 5)"},
+		},
+		{
+			name: "path limits",
+			config: Config{
+				Rule:  Rule{MaxErrors: limit(5)},
+				Paths: []PathRule{{Path: "api/**", Rule: Rule{MaxErrors: limit(1)}}},
+			},
+			issues: issues("api/a.go", 2, models.SeverityError),
+			want:   []string{"api/**: 2 error issues (maximum 1)"},
+		},
+		{
+			name: "path rules decide the severity threshold of their issues",
+			config: Config{
+				Rule:  Rule{FailOn: severity(models.SeverityError)},
+				Paths: []PathRule{{Path: "scripts/**", Rule: Rule{FailOn: severity(models.SeverityCritical)}}},
+			},
+			issues: append(issues("scripts/a.sh", 1, models.SeverityError), issues("b.go", 1, models.SeverityWarning)...),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			verdict := tt.config.Evaluate(tt.issues)
+			if !reflect.DeepEqual(verdict.Reasons, tt.want) {
+				t.Errorf("Evaluate() reasons = %q, want %q", verdict.Reasons, tt.want)
+			}
+			if verdict.Passed != (len(tt.want) == 0) {
+				t.Errorf("Evaluate() passed = %t with reasons %q", verdict.Passed, verdict.Reasons)
+			}
+		})
+	}
+}
+
+func TestLoadFile(t *testing.T) {
+	tests := []struct {
+		name    string
+		content string
+		wantErr bool
+	}{
+		{"empty", "", false},
+		{"valid", "default:\n  max_errors: 5\nrepos:\n  acme/api:\n    paths:\n      - path: legacy/**\n        max_errors: 10\n", false},
+		{"unknown field", "default:\n  max_error: 5\n", true},
+		{"unknown path field", "default:\n  paths:\n    - glob: legacy/**\n", true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), "gates.yml")
+			if err := os.WriteFile(path, []byte(tt.content), 0o644); err != nil {
+				t.Fatal(err)
+			}
+			if _, err := LoadFile(path); (err != nil) != tt.wantErr {
+				t.Errorf("LoadFile() error = %v, wantErr %t", err, tt.wantErr)
+			}
+		})
+	}
+}
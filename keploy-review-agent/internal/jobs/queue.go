@@ -0,0 +1,57 @@
+package jobs
+
+import "sync"
+
+// Queue runs jobs in the background, one at a time per key, such as a pull
+// request. A job submitted while an identical one for the same key is still
+// waiting is dropped, so a burst of duplicate commands runs once.
+type Queue struct {
+	mu      sync.Mutex
+	pending map[string][]queuedJob // waiting jobs by key; present while a worker runs
+}
+
+type queuedJob struct {
+	id  string
+	run func()
+}
+
+func NewQueue() *Queue {
+	return &Queue{pending: make(map[string][]queuedJob)}
+}
+
+// Submit queues run under key and reports whether it was queued, or false
+// when a job with the same id is already waiting for that key.
+func (q *Queue) Submit(key, id string, run func()) bool {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	waiting, busy := q.pending[key]
+	for _, job := range waiting {
+		if job.id == id {
+			return false
+		}
+	}
+	q.pending[key] = append(waiting, queuedJob{id: id, run: run})
+	if !busy {
+		go q.work(key)
+	}
+	return true
+}
+
+// work runs the jobs of key until none are waiting.
+func (q *Queue) work(key string) {
+	for {
+		q.mu.Lock()
+		waiting := q.pending[key]
+		if len(waiting) == 0 {
+			delete(q.pending, key)
+			q.mu.Unlock()
+			return
+		}
+		job := waiting[0]
+		q.pending[key] = waiting[1:]
+		q.mu.Unlock()
+
+		job.run()
+	}
+}
@@ -0,0 +1,56 @@
+package jobs
+
+import (
+	"reflect"
+	"sync"
+	"testing"
+)
+
+func TestQueue(t *testing.T) {
+	q := NewQueue()
+	started, release := make(chan struct{}), make(chan struct{})
+	var mu sync.Mutex
+	var ran []string
+	var wg sync.WaitGroup
+	job := func(name string) func() {
+		wg.Add(1)
+		return func() {
+			defer wg.Done()
+			if name == "first" {
+				close(started)
+				<-release
+			}
+			mu.Lock()
+			ran = append(ran, name)
+			mu.Unlock()
+		}
+	}
+
+	if !q.Submit("pr", "/review", job("first")) {
+		t.Fatal("Submit() dropped the first job")
+	}
+	<-started
+	// The first job is running, so these wait behind it.
+	for _, submit := range []struct {
+		id, name string
+		want     bool
+	}{
+		{"/review", "second", true},
+		{"/review", "duplicate", false},
+		{"/summary", "summary", true},
+	} {
+		run := job(submit.name)
+		if got := q.Submit("pr", submit.id, run); got != submit.want {
+			t.Errorf("Submit(%s) = %t, want %t", submit.name, got, submit.want)
+		}
+		if !submit.want {
+			wg.Done()
+		}
+	}
+	close(release)
+	wg.Wait()
+
+	if want := []string{"first", "second", "summary"}; !reflect.DeepEqual(ran, want) {
+		t.Errorf("ran %v, want %v", ran, want)
+	}
+}
@@ -0,0 +1,89 @@
+package jobs
+
+import (
+	"sort"
+	"sync"
+	"time"
+)
+
+const maxRecords = 200
+
+const (
+	StatusRunning   = "running"
+	StatusCompleted = "completed"
+	StatusFailed    = "failed"
+)
+
+// Record describes a review job and the configuration version it ran with.
+type Record struct {
+	ID            int        `json:"id"`
+	Repo          string     `json:"repo"`
+	PRNumber      int        `json:"pr_number"`
+	Trigger       string     `json:"trigger"` // the webhook event or slash command that started the job
+	ConfigVersion int        `json:"config_version"`
+	Status        string     `json:"status"`
+	Error         string     `json:"error,omitempty"`
+	StartedAt     time.Time  `json:"started_at"`
+	FinishedAt    *time.Time `json:"finished_at,omitempty"`
+}
+
+// Registry keeps the most recent jobs in memory for the admin API.
+type Registry struct {
+	mu      sync.Mutex
+	nextID  int
+	records map[int]*Record
+}
+
+func NewRegistry() *Registry {
+	return &Registry{records: make(map[int]*Record)}
+}
+
+// Start records a new running job and returns its ID.
+func (r *Registry) Start(repo string, prNumber, configVersion int, trigger string) int {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.nextID++
+	r.records[r.nextID] = &Record{
+		ID:            r.nextID,
+		Repo:          repo,
+		PRNumber:      prNumber,
+		Trigger:       trigger,
+		ConfigVersion: configVersion,
+		Status:        StatusRunning,
+		StartedAt:     time.Now(),
+	}
+	delete(r.records, r.nextID-maxRecords)
+	return r.nextID
+}
+
+// Finish marks a job as completed, or failed when err is not nil.
+func (r *Registry) Finish(id int, err error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	record, ok := r.records[id]
+	if !ok {
+		return
+	}
+	now := time.Now()
+	record.FinishedAt = &now
+	record.Status = StatusCompleted
+	if err != nil {
+		record.Status = StatusFailed
+		record.Error = err.Error()
+	}
+}
+
+// List returns copies of the recorded jobs, newest first.
+func (r *Registry) List() []Record {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	list := make([]Record, 0, len(r.records))
+	for _, record := range r.records {
+		list = append(list, *record)
+	}
+	sort.Slice(list, func(i, j int) bool { return list[i].ID > list[j].ID })
+	return list
+}
@@ -0,0 +1,217 @@
+package repoconfig
+
+import (
+	"fmt"
+	"path"
+	"strings"
+
+	"github.com/keploy/keploy-review-agent/internal/analyzer/custom"
+	"github.com/keploy/keploy-review-agent/internal/baseline"
+	"github.com/keploy/keploy-review-agent/internal/config"
+	"github.com/keploy/keploy-review-agent/internal/gate"
+	"github.com/keploy/keploy-review-agent/pkg/models"
+)
+
+// FileName is the repository configuration file, read from the base branch
+// of a pull request so that a pull request cannot weaken its own review.
+const FileName = ".keploy-review.yml"
+
+// RepoConfig mirrors the repository configuration file. Pointer fields
+// distinguish "not set" from zero values so that unset options keep the
+// server defaults.
+type RepoConfig struct {
+	Analyzers struct {
+		Static     *bool `yaml:"static"`
+		Dependency *bool `yaml:"dependency"`
+		LLM        *bool `yaml:"llm"`
+		Custom     *bool `yaml:"custom"`
+	} `yaml:"analyzers"`
+
+	Paths struct {
+		Include []string `yaml:"include"`
+		Exclude []string `yaml:"exclude"`
+	} `yaml:"paths"`
+
+	Severity struct {
+		Min    *models.Severity `yaml:"min"`
+		AIMin  *models.Severity `yaml:"ai_min"`
+		FailOn *models.Severity `yaml:"fail_on"`
+	} `yaml:"severity"`
+
+	CustomRules []custom.Rule `yaml:"custom_rules"`
+
+	LLM struct {
+		PromptAdditions string `yaml:"prompt_additions"`
+	} `yaml:"llm"`
+
+	Comments struct {
+		MaxComments *int `yaml:"max_comments"`
+	} `yaml:"comments"`
+
+	Summary struct {
+		Enabled *bool   `yaml:"enabled"`
+		Target  *string `yaml:"target"`
+	} `yaml:"summary"`
+
+	Baseline struct {
+		File *string `yaml:"file"` // empty disables the baseline
+	} `yaml:"baseline"`
+
+	Gate *gate.Config `yaml:"gate"`
+}
+
+// Settings are the effective options of a single review: the server
+// defaults with the repository configuration applied on top.
+type Settings struct {
+	EnableStatic     bool
+	EnableDependency bool
+	EnableLLM        bool
+	EnableCustom     bool
+
+	Include []string
+	Exclude []string
+
+	MinSeverity    models.Severity
+	AIMinSeverity  models.Severity
+	FailOnSeverity models.Severity
+
+	CustomRules     []custom.Rule
+	PromptAdditions string
+	MaxComments     int // 0 means unlimited
+	Gate            gate.Config
+
+	Summary       bool   // post an LLM walkthrough of the pull request
+	SummaryTarget string // config.SummaryTargetComment or config.SummaryTargetDescription
+
+	BaselineFile string // baseline of grandfathered findings, empty when disabled
+}
+
+// ValidationError lists every problem found in a repository configuration.
+type ValidationError struct {
+	Problems []string
+}
+
+func (e *ValidationError) Error() string {
+	return fmt.Sprintf("invalid %s:\n- %s", FileName, strings.Join(e.Problems, "\n- "))
+}
+
+// Defaults returns the settings used when a repository has no configuration.
+func Defaults(cfg *config.Config, serverGate gate.Config) *Settings {
+	return &Settings{
+		EnableStatic:     cfg.EnableStaticAnalysis,
+		EnableDependency: cfg.EnableDependencyCheck,
+		EnableLLM:        cfg.EnableAI,
+		EnableCustom:     true,
+		MinSeverity:      cfg.MinSeverity,
+		AIMinSeverity:    cfg.AIMinSeverity,
+		FailOnSeverity:   cfg.FailOnSeverity,
+		Gate:             serverGate,
+		Summary:          cfg.SummaryEnabled,
+		SummaryTarget:    cfg.SummaryTarget,
+		BaselineFile:     baseline.FileName,
+	}
+}
+
+// Parse decodes and validates a repository configuration. Unknown keys are
+// rejected so that typos do not silently fall back to defaults.
+func Parse(data []byte) (*RepoConfig, error) {
+	var rc RepoConfig
+
+	problems := config.DecodeYAML(data, &rc)
+	problems = append(problems, rc.validate()...)
+	if len(problems) > 0 {
+		return nil, &ValidationError{Problems: problems}
+	}
+	return &rc, nil
+}
+
+func (rc *RepoConfig) validate() []string {
+	var problems []string
+
+	for _, pattern := range append(append([]string{}, rc.Paths.Include...), rc.Paths.Exclude...) {
+		if _, err := path.Match(pattern, ""); err != nil {
+			problems = append(problems, fmt.Sprintf("paths: invalid glob %q", pattern))
+		}
+	}
+
+	seen := make(map[string]bool)
+	for i := range rc.CustomRules {
+		rule := &rc.CustomRules[i]
+		if err := rule.Compile(); err != nil {
+			problems = append(problems, fmt.Sprintf("custom_rules[%d]: %v", i, err))
+		}
+		if rule.ID != "" && seen[rule.ID] {
+			problems = append(problems, fmt.Sprintf("custom_rules[%d]: duplicate id %q", i, rule.ID))
+		}
+		seen[rule.ID] = true
+	}
+
+	if rc.Comments.MaxComments != nil && *rc.Comments.MaxComments < 0 {
+		problems = append(problems, "comments.max_comments: must not be negative")
+	}
+
+	if t := rc.Summary.Target; t != nil && *t != config.SummaryTargetComment && *t != config.SummaryTargetDescription {
+		problems = append(problems, fmt.Sprintf("summary.target: must be %s or %s, got %q",
+			config.SummaryTargetComment, config.SummaryTargetDescription, *t))
+	}
+
+	if f := rc.Baseline.File; f != nil && (path.IsAbs(*f) || strings.HasPrefix(path.Clean(*f), "..")) {
+		problems = append(problems, fmt.Sprintf("baseline.file: must be a path inside the repository, got %q", *f))
+	}
+
+	if rc.Gate != nil {
+		for i, pr := range rc.Gate.Paths {
+			if pr.Path == "" {
+				problems = append(problems, fmt.Sprintf("gate.paths[%d]: path is required", i))
+			}
+		}
+	}
+
+	return problems
+}
+
+// Apply layers the repository configuration over the settings.
+func (s *Settings) Apply(rc *RepoConfig) {
+	setBool(&s.EnableStatic, rc.Analyzers.Static)
+	setBool(&s.EnableDependency, rc.Analyzers.Dependency)
+	setBool(&s.EnableLLM, rc.Analyzers.LLM)
+	setBool(&s.EnableCustom, rc.Analyzers.Custom)
+
+	s.Include = append(s.Include, rc.Paths.Include...)
+	s.Exclude = append(s.Exclude, rc.Paths.Exclude...)
+
+	if rc.Severity.Min != nil {
+		s.MinSeverity = *rc.Severity.Min
+	}
+	if rc.Severity.AIMin != nil {
+		s.AIMinSeverity = *rc.Severity.AIMin
+	}
+	if rc.Severity.FailOn != nil {
+		s.FailOnSeverity = *rc.Severity.FailOn
+		s.Gate = s.Gate.Merge(gate.Config{Rule: gate.Rule{FailOn: rc.Severity.FailOn}})
+	}
+
+	s.CustomRules = append(s.CustomRules, rc.CustomRules...)
+	if rc.LLM.PromptAdditions != "" {
+		s.PromptAdditions = strings.TrimSpace(s.PromptAdditions + "\n" + rc.LLM.PromptAdditions)
+	}
+	if rc.Comments.MaxComments != nil {
+		s.MaxComments = *rc.Comments.MaxComments
+	}
+	if rc.Gate != nil {
+		s.Gate = s.Gate.Merge(*rc.Gate)
+	}
+	setBool(&s.Summary, rc.Summary.Enabled)
+	if rc.Summary.Target != nil {
+		s.SummaryTarget = *rc.Summary.Target
+	}
+	if rc.Baseline.File != nil {
+		s.BaselineFile = strings.TrimPrefix(*rc.Baseline.File, "./")
+	}
+}
+
+func setBool(dst *bool, value *bool) {
+	if value != nil {
+		*dst = *value
+	}
+}
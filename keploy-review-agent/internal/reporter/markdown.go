@@ -22,9 +22,10 @@ func GenerateMarkdownReport(issues []*models.Issue) string {
 	builder.WriteString("## Summary\n")
 	builder.WriteString("| Severity | Count |\n")
 	builder.WriteString("|----------|-------|\n")
-	builder.WriteString(fmt.Sprintf("| 🔴 Critical | %d |\n", summary[models.SeverityError]))
-	builder.WriteString(fmt.Sprintf("| 🟠 Warning | %d |\n", summary[models.SeverityWarning]))
-	builder.WriteString(fmt.Sprintf("| ℹ️  Info | %d |\n\n", summary[models.SeverityInfo]))
+	for _, severity := range models.Severities {
+		builder.WriteString(fmt.Sprintf("| %s %s | %d |\n", severityEmoji(severity), severityLabel(severity), summary[severity]))
+	}
+	builder.WriteString("\n")
 
 	builder.WriteString("## Detailed Findings\n")
 
@@ -33,25 +34,21 @@ func GenerateMarkdownReport(issues []*models.Issue) string {
 		grouped[issue.Severity] = append(grouped[issue.Severity], issue)
 	}
 
-	severities := []models.Severity{
-		models.SeverityError,
-		models.SeverityWarning,
-		models.SeverityInfo,
-	}
-
-	for _, severity := range severities {
+	for _, severity := range models.Severities {
 		if len(grouped[severity]) == 0 {
 			continue
 		}
 
 		builder.WriteString(fmt.Sprintf("\n### %s %s\n", severityEmoji(severity), severityString(severity)))
-		builder.WriteString("| File | Line | Description | Source | Suggestion |\n")
-		builder.WriteString("|------|------|-------------|--------|------------|\n")
+		builder.WriteString("| File | Line | Rule | Description | Source | Suggestion |\n")
+		builder.WriteString("|------|------|------|-------------|--------|------------|\n")
 
 		for _, issue := range grouped[severity] {
 			line := fmt.Sprintf("%d", issue.Line)
 			if issue.Line == 0 {
 				line = "N/A"
+			} else if issue.EndLine > issue.Line {
+				line = fmt.Sprintf("%d-%d", issue.Line, issue.EndLine)
 			}
 
 			suggestion := issue.Suggestion
@@ -59,11 +56,12 @@ func GenerateMarkdownReport(issues []*models.Issue) string {
 				suggestion = "-"
 			}
 
-			builder.WriteString(fmt.Sprintf("| `%s` | %s | %s | %s | %s |\n",
+			builder.WriteString(fmt.Sprintf("| `%s` | %s | %s | %s | %s | %s |\n",
 				issue.Path,
 				line,
+				ruleCell(issue),
 				escapeMD(issue.Description),
-				issue.Source,
+				issueSources(issue),
 				escapeMD(suggestion),
 			))
 		}
@@ -72,25 +70,70 @@ func GenerateMarkdownReport(issues []*models.Issue) string {
 	return builder.String()
 }
 
-func severityString(s models.Severity) string {
+func ruleCell(issue *models.Issue) string {
+	rule := issue.RuleID
+	if rule == "" {
+		rule = "-"
+	} else if issue.DocURL != "" {
+		rule = fmt.Sprintf("[%s](%s)", escapeMD(rule), issue.DocURL)
+	} else {
+		rule = escapeMD(rule)
+	}
+	if len(issue.Tags) > 0 {
+		rule += " (" + escapeMD(strings.Join(issue.Tags, ", ")) + ")"
+	}
+	return rule
+}
+
+func issueSources(issue *models.Issue) string {
+	if len(issue.Sources) == 0 {
+		return issue.Source
+	}
+	return strings.Join(issue.Sources, ", ")
+}
+
+func severityLabel(s models.Severity) string {
 	switch s {
+	case models.SeverityCritical:
+		return "Critical"
 	case models.SeverityError:
+		return "Error"
+	case models.SeverityWarning:
+		return "Warning"
+	case models.SeverityInfo:
+		return "Info"
+	default:
+		return "Hint"
+	}
+}
+
+func severityString(s models.Severity) string {
+	switch s {
+	case models.SeverityCritical:
 		return "Critical Issues"
+	case models.SeverityError:
+		return "Errors"
 	case models.SeverityWarning:
 		return "Warnings"
-	default:
+	case models.SeverityInfo:
 		return "Info & Suggestions"
+	default:
+		return "Hints"
 	}
 }
 
 func severityEmoji(s models.Severity) string {
 	switch s {
+	case models.SeverityCritical:
+		return "🛑"
 	case models.SeverityError:
 		return "🔴"
 	case models.SeverityWarning:
 		return "🟠"
-	default:
+	case models.SeverityInfo:
 		return "ℹ️"
+	default:
+		return "💡"
 	}
 }
 
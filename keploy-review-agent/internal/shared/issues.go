@@ -1,19 +0,0 @@
-package shared
-
-import (
-	"fmt"
-
-	"github.com/keploy/keploy-review-agent/pkg/models"
-)
-
-var AllIssues []*models.Issue
-
-func GetAllIssues() []*models.Issue {
-	return AllIssues
-}
-
-func AddIssue(issue *models.Issue) error {
-	fmt.Printf("Issue: %+v\n", issue)
-	AllIssues = append(AllIssues, issue)
-	return nil
-}
@@ -0,0 +1,226 @@
+package workspace
+
+import (
+	"archive/tar"
+	"bytes"
+	"compress/gzip"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"syscall"
+
+	"github.com/keploy/keploy-review-agent/internal/config"
+	"github.com/keploy/keploy-review-agent/pkg/github"
+)
+
+// Workspace is a checkout of a repository at one commit in a temporary
+// directory.
+type Workspace struct {
+	Dir    string
+	Method string // config.CheckoutGit or config.CheckoutTarball
+}
+
+// Close removes the checkout.
+func (w *Workspace) Close() error {
+	return os.RemoveAll(w.Dir)
+}
+
+// Checkout fetches a repository at commit sha with the method given by mode.
+// In auto mode a failed git fetch, as on hosts without git, falls back to
+// the tarball API.
+func Checkout(ctx context.Context, client *github.Client, owner, repo, sha, mode string) (*Workspace, error) {
+	if mode == config.CheckoutOff {
+		return nil, errors.New("checkouts are disabled")
+	}
+	if sha == "" {
+		return nil, errors.New("no commit to check out")
+	}
+
+	dir, err := os.MkdirTemp("", "keploy-review-checkout-")
+	if err != nil {
+		return nil, fmt.Errorf("failed to create checkout directory: %w", err)
+	}
+	w := &Workspace{Dir: dir}
+
+	if mode == config.CheckoutAuto || mode == config.CheckoutGit {
+		err = fetchGit(ctx, dir, client.CloneURL(owner, repo), client.GitAuthHeader(), sha)
+		if err == nil {
+			w.Method = config.CheckoutGit
+			return w, nil
+		}
+		if mode == config.CheckoutGit {
+			w.Close()
+			return nil, err
+		}
+		log.Printf("Warning: git checkout of %s/%s failed, downloading the tarball instead: %v", owner, repo, err)
+		if err := resetDir(dir); err != nil {
+			w.Close()
+			return nil, err
+		}
+	}
+
+	if err := fetchTarball(ctx, dir, client, owner, repo, sha); err != nil {
+		w.Close()
+		return nil, err
+	}
+	w.Method = config.CheckoutTarball
+	return w, nil
+}
+
+// fetchGit makes a shallow clone of a single commit. GitHub serves any
+// commit of the repository by SHA, including the heads of pull requests
+// from forks.
+func fetchGit(ctx context.Context, dir, cloneURL, authHeader, sha string) error {
+	steps := [][]string{
+		{"init", "--quiet"},
+		{"-c", "http.extraHeader=" + authHeader, "fetch", "--quiet", "--depth=1", "--no-tags", cloneURL, sha},
+		{"checkout", "--quiet", "--detach", "FETCH_HEAD"},
+	}
+	for _, args := range steps {
+		cmd := exec.CommandContext(ctx, "git", args...)
+		cmd.Dir = dir
+		cmd.Env = append(os.Environ(), "GIT_TERMINAL_PROMPT=0")
+		var stderr bytes.Buffer
+		cmd.Stderr = &stderr
+		if err := cmd.Run(); err != nil {
+			// The arguments hold the token; name the subcommand only.
+			subcommand := args[0]
+			if subcommand == "-c" {
+				subcommand = args[2]
+			}
+			return fmt.Errorf("git %s failed: %w: %s", subcommand, err, strings.TrimSpace(stderr.String()))
+		}
+	}
+	return nil
+}
+
+// fetchTarball extracts the repository archive into dir.
+func fetchTarball(ctx context.Context, dir string, client *github.Client, owner, repo, sha string) error {
+	archive, err := client.DownloadTarball(ctx, owner, repo, sha)
+	if err != nil {
+		return fmt.Errorf("failed to download tarball: %w", err)
+	}
+	defer archive.Close()
+	return extractTarball(dir, archive)
+}
+
+// extractTarball extracts a gzipped tar archive into dir, without the
+// top-level directory GitHub wraps it in. Only directories and regular files
+// are extracted: symbolic links are skipped, so that no entry can write
+// outside dir through a link, and entries whose path would leave dir or
+// pass through an existing link are dropped.
+func extractTarball(dir string, archive io.Reader) error {
+	gz, err := gzip.NewReader(archive)
+	if err != nil {
+		return fmt.Errorf("failed to read tarball: %w", err)
+	}
+	defer gz.Close()
+
+	tr := tar.NewReader(gz)
+	for {
+		header, err := tr.Next()
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return fmt.Errorf("failed to read tarball: %w", err)
+		}
+
+		name := header.Name
+		if i := strings.IndexByte(name, '/'); i >= 0 {
+			name = name[i+1:]
+		} else {
+			continue // the top-level directory itself
+		}
+		target, ok := within(dir, name)
+		if !ok || name == "" || linkedParent(dir, target) {
+			continue
+		}
+
+		switch header.Typeflag {
+		case tar.TypeDir:
+			if err := os.MkdirAll(target, 0o755); err != nil {
+				return fmt.Errorf("failed to extract %s: %w", name, err)
+			}
+		case tar.TypeReg:
+			if err := extractFile(tr, target, header.FileInfo().Mode()); err != nil {
+				return fmt.Errorf("failed to extract %s: %w", name, err)
+			}
+		}
+	}
+}
+
+// extractFile writes a regular file. O_NOFOLLOW fails rather than write
+// through a link already at target.
+func extractFile(r io.Reader, target string, mode os.FileMode) error {
+	if err := os.MkdirAll(filepath.Dir(target), 0o755); err != nil {
+		return err
+	}
+	f, err := os.OpenFile(target, os.O_CREATE|os.O_WRONLY|os.O_TRUNC|syscall.O_NOFOLLOW, mode.Perm()|0o600)
+	if err != nil {
+		return err
+	}
+	if _, err := io.Copy(f, r); err != nil {
+		f.Close()
+		return err
+	}
+	return f.Close()
+}
+
+// within returns the path of name inside dir, and false when name escapes
+// dir.
+func within(dir, name string) (string, bool) {
+	if filepath.IsAbs(filepath.FromSlash(name)) {
+		return "", false
+	}
+	target := filepath.Join(dir, filepath.FromSlash(name))
+	rel, err := filepath.Rel(dir, target)
+	if err != nil || rel == ".." || strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
+		return "", false
+	}
+	return target, true
+}
+
+// linkedParent reports whether a directory between dir and target is a
+// symbolic link, through which target could lie outside dir.
+func linkedParent(dir, target string) bool {
+	rel, err := filepath.Rel(dir, filepath.Dir(target))
+	if err != nil {
+		return true
+	}
+	if rel == "." {
+		return false
+	}
+	current := dir
+	for _, part := range strings.Split(rel, string(filepath.Separator)) {
+		current = filepath.Join(current, part)
+		info, err := os.Lstat(current)
+		if os.IsNotExist(err) {
+			return false // created by MkdirAll as a plain directory
+		}
+		if err != nil || info.Mode()&os.ModeSymlink != 0 {
+			return true
+		}
+	}
+	return false
+}
+
+// resetDir empties dir after a failed checkout attempt.
+func resetDir(dir string) error {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return fmt.Errorf("failed to clean checkout directory: %w", err)
+	}
+	for _, entry := range entries {
+		if err := os.RemoveAll(filepath.Join(dir, entry.Name())); err != nil {
+			return fmt.Errorf("failed to clean checkout directory: %w", err)
+		}
+	}
+	return nil
+}
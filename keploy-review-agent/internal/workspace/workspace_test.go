@@ -0,0 +1,192 @@
+package workspace
+
+import (
+	"archive/tar"
+	"bytes"
+	"compress/gzip"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+type entry struct {
+	name     string
+	typeflag byte
+	body     string
+	linkname string
+}
+
+func tarball(t *testing.T, entries []entry) *bytes.Buffer {
+	t.Helper()
+	var buf bytes.Buffer
+	gz := gzip.NewWriter(&buf)
+	tw := tar.NewWriter(gz)
+	for _, e := range entries {
+		header := &tar.Header{Name: e.name, Typeflag: e.typeflag, Linkname: e.linkname, Mode: 0o644}
+		if e.typeflag == tar.TypeReg {
+			header.Size = int64(len(e.body))
+		}
+		if e.typeflag == tar.TypeDir {
+			header.Mode = 0o755
+		}
+		if err := tw.WriteHeader(header); err != nil {
+			t.Fatal(err)
+		}
+		if _, err := tw.Write([]byte(e.body)); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := tw.Close(); err != nil {
+		t.Fatal(err)
+	}
+	if err := gz.Close(); err != nil {
+		t.Fatal(err)
+	}
+	return &buf
+}
+
+// tree lists the files and links under dir with the content of the files.
+func tree(t *testing.T, dir string) map[string]string {
+	t.Helper()
+	files := make(map[string]string)
+	err := filepath.Walk(dir, func(path string, info os.FileInfo, err error) error {
+		if err != nil || path == dir || info.IsDir() {
+			return err
+		}
+		rel, _ := filepath.Rel(dir, path)
+		rel = filepath.ToSlash(rel)
+		if info.Mode()&os.ModeSymlink != 0 {
+			files[rel] = "-> link"
+			return nil
+		}
+		data, err := os.ReadFile(path)
+		files[rel] = string(data)
+		return err
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return files
+}
+
+func TestExtractTarball(t *testing.T) {
+	tests := []struct {
+		name    string
+		entries []entry
+		// setup prepares the checkout directory, given the directory
+		// outside it that escaping entries would write to.
+		setup   func(t *testing.T, dir, outside string)
+		want    map[string]string
+		wantErr bool
+	}{
+		{
+			name: "strips the top-level directory",
+			entries: []entry{
+				{name: "repo-abc/", typeflag: tar.TypeDir},
+				{name: "repo-abc/go.mod", typeflag: tar.TypeReg, body: "module x\n"},
+				{name: "repo-abc/pkg/", typeflag: tar.TypeDir},
+				{name: "repo-abc/pkg/a.go", typeflag: tar.TypeReg, body: "package pkg\n"},
+			},
+			want: map[string]string{"go.mod": "module x\n", "pkg/a.go": "package pkg\n"},
+		},
+		{
+			name: "drops entries leaving the directory",
+			entries: []entry{
+				{name: "repo-abc/../escaped", typeflag: tar.TypeReg, body: "x"},
+				{name: "repo-abc/a/../../escaped", typeflag: tar.TypeReg, body: "x"},
+				{name: "repo-abc/kept", typeflag: tar.TypeReg, body: "y"},
+			},
+			want: map[string]string{"kept": "y"},
+		},
+		{
+			name: "skips symbolic and hard links",
+			entries: []entry{
+				{name: "repo-abc/inside", typeflag: tar.TypeSymlink, linkname: "kept"},
+				{name: "repo-abc/outside", typeflag: tar.TypeSymlink, linkname: "../../etc"},
+				{name: "repo-abc/hard", typeflag: tar.TypeLink, linkname: "repo-abc/kept"},
+				{name: "repo-abc/kept", typeflag: tar.TypeReg, body: "y"},
+			},
+			want: map[string]string{"kept": "y"},
+		},
+		{
+			name: "does not write through a link to a directory",
+			entries: []entry{
+				{name: "repo-abc/link", typeflag: tar.TypeSymlink, linkname: "../outside"},
+				{name: "repo-abc/link/escaped", typeflag: tar.TypeReg, body: "x"},
+				{name: "repo-abc/link/", typeflag: tar.TypeDir},
+			},
+			setup: func(t *testing.T, dir, outside string) {
+				if err := os.Symlink(outside, filepath.Join(dir, "link")); err != nil {
+					t.Fatal(err)
+				}
+			},
+			want: map[string]string{"link": "-> link"},
+		},
+		{
+			name: "does not write through a link to a file",
+			entries: []entry{
+				{name: "repo-abc/file", typeflag: tar.TypeReg, body: "x"},
+			},
+			setup: func(t *testing.T, dir, outside string) {
+				if err := os.Symlink(filepath.Join(outside, "file"), filepath.Join(dir, "file")); err != nil {
+					t.Fatal(err)
+				}
+			},
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			root := t.TempDir()
+			dir := filepath.Join(root, "checkout")
+			outside := filepath.Join(root, "outside")
+			for _, d := range []string{dir, outside} {
+				if err := os.Mkdir(d, 0o755); err != nil {
+					t.Fatal(err)
+				}
+			}
+			if tt.setup != nil {
+				tt.setup(t, dir, outside)
+			}
+
+			err := extractTarball(dir, tarball(t, tt.entries))
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("extractTarball() error = %v, wantErr %t", err, tt.wantErr)
+			}
+			if leaked := tree(t, outside); len(leaked) > 0 {
+				t.Errorf("extractTarball() wrote outside the directory: %v", leaked)
+			}
+			if _, err := os.Stat(filepath.Join(root, "escaped")); !os.IsNotExist(err) {
+				t.Errorf("extractTarball() wrote %s", filepath.Join(root, "escaped"))
+			}
+			if tt.wantErr {
+				return
+			}
+			if got := tree(t, dir); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("extractTarball() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestWithin(t *testing.T) {
+	dir := filepath.FromSlash("/tmp/checkout")
+	tests := []struct {
+		name string
+		want bool
+	}{
+		{"a/b.go", true},
+		{"a/../b.go", true},
+		{"..b.go", true},
+		{"..", false},
+		{"../b.go", false},
+		{"a/../../b.go", false},
+	}
+	for _, tt := range tests {
+		if _, ok := within(dir, tt.name); ok != tt.want {
+			t.Errorf("within(%q) = %t, want %t", tt.name, ok, tt.want)
+		}
+	}
+}
@@ -0,0 +1,58 @@
+package github
+
+import (
+	"context"
+	"encoding/base64"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"strings"
+)
+
+// CloneURL returns the HTTPS git URL of a repository on the server of the
+// client's API.
+func (c *Client) CloneURL(owner, repo string) string {
+	base := strings.TrimSuffix(c.baseURL, "/")
+	switch {
+	case base == "https://api.github.com":
+		base = "https://github.com"
+	case strings.HasSuffix(base, "/api/v3"): // GitHub Enterprise Server
+		base = strings.TrimSuffix(base, "/api/v3")
+	}
+	return fmt.Sprintf("%s/%s/%s.git", base, owner, repo)
+}
+
+// GitAuthHeader returns the HTTP header authenticating git with the client's
+// token, to pass as http.extraHeader rather than embedding the token in the
+// clone URL.
+func (c *Client) GitAuthHeader() string {
+	credentials := base64.StdEncoding.EncodeToString([]byte("x-access-token:" + c.token))
+	return "Authorization: Basic " + credentials
+}
+
+// DownloadTarball returns the gzipped tar archive of a repository at ref.
+// Archives can be large, so the download is bounded by ctx only, not by the
+// client's request timeout. The caller closes the archive.
+func (c *Client) DownloadTarball(ctx context.Context, owner, repo, ref string) (io.ReadCloser, error) {
+	apiURL := fmt.Sprintf("%s/repos/%s/%s/tarball/%s", c.baseURL, owner, repo, url.PathEscape(ref))
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, apiURL, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create request: %w", err)
+	}
+	req.Header.Set("Authorization", "token "+c.token)
+	req.Header.Set("Accept", "application/vnd.github.v3+json")
+
+	client := &http.Client{Transport: c.httpClient.Transport}
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to send request: %w", err)
+	}
+	if resp.StatusCode != http.StatusOK {
+		defer resp.Body.Close()
+		body, _ := ioutil.ReadAll(resp.Body)
+		return nil, &APIError{StatusCode: resp.StatusCode, Status: resp.Status, Body: string(body)}
+	}
+	return resp.Body, nil
+}
@@ -0,0 +1,93 @@
+package github
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"strings"
+)
+
+type PullRequest struct {
+	Number int    `json:"number"`
+	Title  string `json:"title"`
+	Body   string `json:"body"`
+	Head   struct {
+		Ref  string      `json:"ref"`
+		Sha  string      `json:"sha"`
+		Repo *Repository `json:"repo"` // nil when the fork was deleted
+	} `json:"head"`
+	Base struct {
+		Ref  string      `json:"ref"`
+		Sha  string      `json:"sha"`
+		Repo *Repository `json:"repo"`
+	} `json:"base"`
+}
+
+type Repository struct {
+	FullName string `json:"full_name"`
+}
+
+// FromFork reports whether the head of a pull request is in another
+// repository than its base.
+func (pr *PullRequest) FromFork() bool {
+	return pr.Head.Repo == nil || pr.Base.Repo == nil || !strings.EqualFold(pr.Head.Repo.FullName, pr.Base.Repo.FullName)
+}
+
+// Check run conclusions understood by the GitHub checks API.
+const (
+	ConclusionSuccess = "success"
+	ConclusionNeutral = "neutral"
+	ConclusionFailure = "failure"
+)
+
+func (c *Client) GetPullRequest(ctx context.Context, owner, repo string, pullNumber int) (*PullRequest, error) {
+	url := fmt.Sprintf("%s/repos/%s/%s/pulls/%d", c.baseURL, owner, repo, pullNumber)
+	var pr PullRequest
+	if err := c.getJSON(ctx, url, &pr); err != nil {
+		return nil, err
+	}
+	return &pr, nil
+}
+
+// UpdatePullRequestBody replaces the description of a pull request.
+func (c *Client) UpdatePullRequestBody(ctx context.Context, owner, repo string, pullNumber int, body string) error {
+	url := fmt.Sprintf("%s/repos/%s/%s/pulls/%d", c.baseURL, owner, repo, pullNumber)
+	if err := c.doJSON(ctx, http.MethodPatch, url, map[string]string{"body": body}, nil); err != nil {
+		return fmt.Errorf("failed to update pull request: %w", err)
+	}
+	return nil
+}
+
+// CreateCheckRun reports a completed check run on the given commit.
+func (c *Client) CreateCheckRun(ctx context.Context, owner, repo, headSHA, name, conclusion, title, summary string) error {
+	url := fmt.Sprintf("%s/repos/%s/%s/check-runs", c.baseURL, owner, repo)
+	payload := map[string]interface{}{
+		"name":       name,
+		"head_sha":   headSHA,
+		"status":     "completed",
+		"conclusion": conclusion,
+		"output": map[string]interface{}{
+			"title":   title,
+			"summary": summary,
+		},
+	}
+	if err := c.doJSON(ctx, http.MethodPost, url, payload, nil); err != nil {
+		return fmt.Errorf("failed to create check run: %w", err)
+	}
+	return nil
+}
+
+// CreateCommitStatus sets a commit status; state is one of "success",
+// "failure", "error" or "pending".
+func (c *Client) CreateCommitStatus(ctx context.Context, owner, repo, sha, state, statusContext, description string) error {
+	url := fmt.Sprintf("%s/repos/%s/%s/statuses/%s", c.baseURL, owner, repo, sha)
+	payload := map[string]interface{}{
+		"state":       state,
+		"context":     statusContext,
+		"description": description,
+	}
+	if err := c.doJSON(ctx, http.MethodPost, url, payload, nil); err != nil {
+		return fmt.Errorf("failed to create commit status: %w", err)
+	}
+	return nil
+}
@@ -11,9 +11,7 @@ import (
 	"net/http"
 	"time"
 
-	"os"
 
-	"github.com/keploy/keploy-review-agent/internal/shared"
 	"github.com/keploy/keploy-review-agent/pkg/models"
 )
 
@@ -215,6 +213,8 @@ func (c *Client) GetChangedFiles(ctx context.Context, owner, repo string, pullNu
 		Filename string `json:"filename"`
 		Status   string `json:"status"`
 		RawURL   string `json:"raw_url"`
+		Patch    string `json:"patch"`
+		SHA      string `json:"sha"`
 	}
 
 	if err := json.NewDecoder(resp.Body).Decode(&prFiles); err != nil {
@@ -235,6 +235,8 @@ func (c *Client) GetChangedFiles(ctx context.Context, owner, repo string, pullNu
 		files = append(files, &models.File{
 			Path:    prFile.Filename,
 			Content: content,
+			Patch:   prFile.Patch,
+			SHA:     prFile.SHA,
 		})
 	}
 
@@ -358,7 +360,13 @@ func fetchRawContent(ctx context.Context, rawURL string) (string, error) {
 // 	return nil
 // }
 
-func (c *Client) CreateReview(ctx context.Context, owner, repo string, pullnumber int, comments []*models.ReviewComment) error {
+// Review events understood by the pull request reviews API.
+const (
+	ReviewEventComment        = "COMMENT"
+	ReviewEventRequestChanges = "REQUEST_CHANGES"
+)
+
+func (c *Client) CreateReview(ctx context.Context, owner, repo string, pullnumber int, event, summary string, comments []*models.ReviewComment) error {
 	// Format the comment
 	var markdownComment string
 	markdownComment += "### 📝 Automated Review Comments\n\n"
@@ -371,19 +379,43 @@ func (c *Client) CreateReview(ctx context.Context, owner, repo string, pullnumbe
 		)
 	}
 
-	// Generate filename using current timestamp
-	timestamp := time.Now().Format("2006-01-02_15-04-05")
-	filename := fmt.Sprintf("Before_review_%s.md", timestamp)
-
-	// Write to file
-	err := os.WriteFile(filename, []byte(markdownComment), 0644)
-	if err != nil {
-		return fmt.Errorf("failed to write review to file: %w", err)
+	if err := c.postReview(ctx, owner, repo, pullnumber, event, summary, comments); err != nil {
+		// Inline comments are rejected when a line is outside the diff, so
+		// fall back to a review that lists every comment in its body.
+		log.Printf("Failed to post inline review, retrying without inline comments: %v", err)
+		if err := c.postReview(ctx, owner, repo, pullnumber, event, summary+"\n\n"+markdownComment, nil); err != nil {
+			return fmt.Errorf("failed to post review: %w", err)
+		}
 	}
 
-	log.Printf("*********************************************************************************************Review comments saved to file: %s\n", filename)
 	return nil
 }
+func (c *Client) postReview(ctx context.Context, owner, repo string, pullnumber int, event, body string, comments []*models.ReviewComment) error {
+	url := fmt.Sprintf("%s/repos/%s/%s/pulls/%d/reviews", c.baseURL, owner, repo, pullnumber)
+
+	var inline []map[string]interface{}
+	for _, comment := range comments {
+		if comment.Line <= 0 {
+			continue
+		}
+		inline = append(inline, map[string]interface{}{
+			"path": comment.Path,
+			"line": comment.Line,
+			"side": "RIGHT",
+			"body": comment.Body,
+		})
+	}
+
+	payload := map[string]interface{}{
+		"event": event,
+		"body":  body,
+	}
+	if len(inline) > 0 {
+		payload["comments"] = inline
+	}
+	return c.doJSON(ctx, http.MethodPost, url, payload, nil)
+}
+
 func base64Decode(content string) ([]byte, error) {
 	decoded, err := base64.StdEncoding.DecodeString(content)
 	if err != nil {
@@ -399,8 +431,6 @@ func (c *Client) ProcessPullRequestReview(ctx context.Context, owner, repo strin
 		return fmt.Errorf("failed to get changed files: %w", err)
 	}
 	fmt.Println("changedFiles: track01 ", changedFiles)
-	ghoda := shared.GetAllIssues()
-	fmt.Println("ghoda: track01 ", ghoda)
 
 	var reviewComments []*models.ReviewComment
 	for _, file := range changedFiles {
@@ -0,0 +1,27 @@
+package github
+
+import (
+	"context"
+	"fmt"
+)
+
+// GetPermission returns the repository role of a user: admin, maintain,
+// write, triage, read or none.
+func (c *Client) GetPermission(ctx context.Context, owner, repo, user string) (string, error) {
+	url := fmt.Sprintf("%s/repos/%s/%s/collaborators/%s/permission", c.baseURL, owner, repo, user)
+	var result struct {
+		Permission string `json:"permission"` // admin, write, read or none
+		RoleName   string `json:"role_name"`  // also maintain and triage, or a custom role
+	}
+	if err := c.getJSON(ctx, url, &result); err != nil {
+		if IsNotFound(err) {
+			return "none", nil
+		}
+		return "", fmt.Errorf("failed to fetch permission of %s: %w", user, err)
+	}
+	switch result.RoleName {
+	case "admin", "maintain", "write", "triage", "read":
+		return result.RoleName, nil
+	}
+	return result.Permission, nil
+}
@@ -0,0 +1,155 @@
+package github
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"strings"
+)
+
+const perPage = 100
+
+type Comment struct {
+	ID          int64  `json:"id"`
+	Body        string `json:"body"`
+	Path        string `json:"path"`
+	Line        int    `json:"line"`
+	DiffHunk    string `json:"diff_hunk"`
+	InReplyToID int64  `json:"in_reply_to_id"` // root of the thread for review comment replies
+	User        struct {
+		Login string `json:"login"`
+		Type  string `json:"type"`
+	} `json:"user"`
+	App *struct {
+		ID int64 `json:"id"`
+	} `json:"performed_via_github_app"` // set on comments posted by a GitHub App
+	Reactions struct {
+		ThumbsUp   int `json:"+1"`
+		ThumbsDown int `json:"-1"`
+	} `json:"reactions"`
+}
+
+// ListReviewComments returns all inline review comments on a pull request.
+func (c *Client) ListReviewComments(ctx context.Context, owner, repo string, pullNumber int) ([]*Comment, error) {
+	url := fmt.Sprintf("%s/repos/%s/%s/pulls/%d/comments", c.baseURL, owner, repo, pullNumber)
+	return c.listComments(ctx, url)
+}
+
+// ListIssueComments returns all conversation comments on a pull request.
+func (c *Client) ListIssueComments(ctx context.Context, owner, repo string, pullNumber int) ([]*Comment, error) {
+	url := fmt.Sprintf("%s/repos/%s/%s/issues/%d/comments", c.baseURL, owner, repo, pullNumber)
+	return c.listComments(ctx, url)
+}
+
+// GetReviewComment returns a single inline review comment.
+func (c *Client) GetReviewComment(ctx context.Context, owner, repo string, commentID int64) (*Comment, error) {
+	url := fmt.Sprintf("%s/repos/%s/%s/pulls/comments/%d", c.baseURL, owner, repo, commentID)
+	var comment Comment
+	if err := c.getJSON(ctx, url, &comment); err != nil {
+		return nil, err
+	}
+	return &comment, nil
+}
+
+// ReplyToReviewComment posts a reply in the thread of an inline review comment.
+func (c *Client) ReplyToReviewComment(ctx context.Context, owner, repo string, pullNumber int, commentID int64, body string) error {
+	url := fmt.Sprintf("%s/repos/%s/%s/pulls/%d/comments/%d/replies", c.baseURL, owner, repo, pullNumber, commentID)
+	if err := c.doJSON(ctx, http.MethodPost, url, map[string]string{"body": body}, nil); err != nil {
+		return fmt.Errorf("failed to reply to comment: %w", err)
+	}
+	return nil
+}
+
+func (c *Client) listComments(ctx context.Context, url string) ([]*Comment, error) {
+	var all []*Comment
+	for page := 1; ; page++ {
+		var comments []*Comment
+		if err := c.getJSON(ctx, fmt.Sprintf("%s?per_page=%d&page=%d", url, perPage, page), &comments); err != nil {
+			return nil, err
+		}
+		all = append(all, comments...)
+		if len(comments) < perPage {
+			return all, nil
+		}
+	}
+}
+
+func (c *Client) CreateIssueComment(ctx context.Context, owner, repo string, pullNumber int, body string) error {
+	url := fmt.Sprintf("%s/repos/%s/%s/issues/%d/comments", c.baseURL, owner, repo, pullNumber)
+	if err := c.doJSON(ctx, http.MethodPost, url, map[string]string{"body": body}, nil); err != nil {
+		return fmt.Errorf("failed to create comment: %w", err)
+	}
+	return nil
+}
+
+// UpsertIssueComment keeps a single "sticky" comment per marker: the first
+// comment containing the marker is updated, otherwise a new one is created.
+func (c *Client) UpsertIssueComment(ctx context.Context, owner, repo string, pullNumber int, marker, body string) error {
+	if !strings.Contains(body, marker) {
+		body += "\n\n" + marker
+	}
+
+	comments, err := c.ListIssueComments(ctx, owner, repo, pullNumber)
+	if err != nil {
+		return err
+	}
+	for _, comment := range comments {
+		if strings.Contains(comment.Body, marker) {
+			url := fmt.Sprintf("%s/repos/%s/%s/issues/comments/%d", c.baseURL, owner, repo, comment.ID)
+			if err := c.doJSON(ctx, http.MethodPatch, url, map[string]string{"body": body}, nil); err != nil {
+				return fmt.Errorf("failed to update comment: %w", err)
+			}
+			return nil
+		}
+	}
+	return c.CreateIssueComment(ctx, owner, repo, pullNumber, body)
+}
+
+// DeleteIssueComments deletes the sticky comments containing the marker, if
+// any.
+func (c *Client) DeleteIssueComments(ctx context.Context, owner, repo string, pullNumber int, marker string) error {
+	comments, err := c.ListIssueComments(ctx, owner, repo, pullNumber)
+	if err != nil {
+		return err
+	}
+	for _, comment := range comments {
+		if !strings.Contains(comment.Body, marker) {
+			continue
+		}
+		url := fmt.Sprintf("%s/repos/%s/%s/issues/comments/%d", c.baseURL, owner, repo, comment.ID)
+		if err := c.doJSON(ctx, http.MethodDelete, url, nil, nil); err != nil && !IsNotFound(err) {
+			return fmt.Errorf("failed to delete comment: %w", err)
+		}
+	}
+	return nil
+}
+
+// Reaction is an emoji reaction to a comment.
+type Reaction struct {
+	Content string `json:"content"` // +1, -1, laugh, confused, heart, hooray, rocket or eyes
+	User    struct {
+		Login string `json:"login"`
+		Type  string `json:"type"`
+	} `json:"user"`
+}
+
+// ListReactions returns the reactions to an inline review comment or, unless
+// review, to a conversation comment.
+func (c *Client) ListReactions(ctx context.Context, owner, repo string, commentID int64, review bool) ([]*Reaction, error) {
+	kind := "issues"
+	if review {
+		kind = "pulls"
+	}
+	url := fmt.Sprintf("%s/repos/%s/%s/%s/comments/%d/reactions", c.baseURL, owner, repo, kind, commentID)
+	var all []*Reaction
+	for page := 1; ; page++ {
+		var reactions []*Reaction
+		if err := c.getJSON(ctx, fmt.Sprintf("%s?per_page=%d&page=%d", url, perPage, page), &reactions); err != nil {
+			return nil, fmt.Errorf("failed to list reactions: %w", err)
+		}
+		all = append(all, reactions...)
+		if len(reactions) < perPage {
+			return all, nil
+		}
+	}
+}
@@ -0,0 +1,56 @@
+package github
+
+import (
+	"context"
+	"encoding/base64"
+	"fmt"
+	"net/url"
+	"strings"
+)
+
+// GetFileContent returns the content of a file at the given ref. Missing
+// files are reported with an error satisfying IsNotFound.
+func (c *Client) GetFileContent(ctx context.Context, owner, repo, path, ref string) (string, error) {
+	apiURL := fmt.Sprintf("%s/repos/%s/%s/contents/%s?ref=%s",
+		c.baseURL, owner, repo, strings.TrimPrefix(path, "/"), url.QueryEscape(ref))
+
+	var file struct {
+		Content  string `json:"content"`
+		Encoding string `json:"encoding"`
+	}
+	if err := c.getJSON(ctx, apiURL, &file); err != nil {
+		return "", err
+	}
+	if file.Encoding != "base64" {
+		return "", fmt.Errorf("unsupported content encoding %q for %s", file.Encoding, path)
+	}
+
+	decoded, err := base64.StdEncoding.DecodeString(strings.ReplaceAll(file.Content, "\n", ""))
+	if err != nil {
+		return "", fmt.Errorf("failed to decode %s: %w", path, err)
+	}
+	return string(decoded), nil
+}
+
+// GetDefaultBranch returns the name of the default branch of a repository.
+func (c *Client) GetDefaultBranch(ctx context.Context, owner, repo string) (string, error) {
+	var repository struct {
+		DefaultBranch string `json:"default_branch"`
+	}
+	if err := c.getJSON(ctx, fmt.Sprintf("%s/repos/%s/%s", c.baseURL, owner, repo), &repository); err != nil {
+		return "", err
+	}
+	return repository.DefaultBranch, nil
+}
+
+// ResolveCommit returns the SHA of the commit a branch, tag or SHA points to.
+func (c *Client) ResolveCommit(ctx context.Context, owner, repo, ref string) (string, error) {
+	var commit struct {
+		SHA string `json:"sha"`
+	}
+	apiURL := fmt.Sprintf("%s/repos/%s/%s/commits/%s", c.baseURL, owner, repo, url.PathEscape(ref))
+	if err := c.getJSON(ctx, apiURL, &commit); err != nil {
+		return "", err
+	}
+	return commit.SHA, nil
+}
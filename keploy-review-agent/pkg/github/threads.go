@@ -0,0 +1,100 @@
+package github
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+)
+
+// ReviewThread is a thread of inline review comments.
+type ReviewThread struct {
+	Resolved   bool
+	ResolvedBy string // login of the user who resolved the thread
+	RootID     int64  // ID of the first comment of the thread
+	RootBody   string // body of the first comment of the thread
+}
+
+const reviewThreadsQuery = `query($owner: String!, $name: String!, $number: Int!, $cursor: String) {
+  repository(owner: $owner, name: $name) {
+    pullRequest(number: $number) {
+      reviewThreads(first: 100, after: $cursor) {
+        nodes {
+          isResolved
+          resolvedBy { login }
+          comments(first: 1) { nodes { databaseId body } }
+        }
+        pageInfo { hasNextPage endCursor }
+      }
+    }
+  }
+}`
+
+// ListReviewThreads returns the review threads of a pull request with their
+// resolution state, which only the GraphQL API exposes.
+func (c *Client) ListReviewThreads(ctx context.Context, owner, repo string, pullNumber int) ([]*ReviewThread, error) {
+	var threads []*ReviewThread
+	var cursor *string
+	for {
+		var result struct {
+			Data struct {
+				Repository struct {
+					PullRequest struct {
+						ReviewThreads struct {
+							Nodes []struct {
+								IsResolved bool `json:"isResolved"`
+								ResolvedBy *struct {
+									Login string `json:"login"`
+								} `json:"resolvedBy"`
+								Comments struct {
+									Nodes []struct {
+										DatabaseID int64  `json:"databaseId"`
+										Body       string `json:"body"`
+									} `json:"nodes"`
+								} `json:"comments"`
+							} `json:"nodes"`
+							PageInfo struct {
+								HasNextPage bool   `json:"hasNextPage"`
+								EndCursor   string `json:"endCursor"`
+							} `json:"pageInfo"`
+						} `json:"reviewThreads"`
+					} `json:"pullRequest"`
+				} `json:"repository"`
+			} `json:"data"`
+			Errors []struct {
+				Message string `json:"message"`
+			} `json:"errors"`
+		}
+		payload := map[string]interface{}{
+			"query": reviewThreadsQuery,
+			"variables": map[string]interface{}{
+				"owner":  owner,
+				"name":   repo,
+				"number": pullNumber,
+				"cursor": cursor,
+			},
+		}
+		if err := c.doJSON(ctx, http.MethodPost, c.baseURL+"/graphql", payload, &result); err != nil {
+			return nil, fmt.Errorf("failed to list review threads: %w", err)
+		}
+		if len(result.Errors) > 0 {
+			return nil, fmt.Errorf("failed to list review threads: %s", result.Errors[0].Message)
+		}
+
+		page := result.Data.Repository.PullRequest.ReviewThreads
+		for _, node := range page.Nodes {
+			if len(node.Comments.Nodes) == 0 {
+				continue
+			}
+			root := node.Comments.Nodes[0]
+			thread := &ReviewThread{Resolved: node.IsResolved, RootID: root.DatabaseID, RootBody: root.Body}
+			if node.ResolvedBy != nil {
+				thread.ResolvedBy = node.ResolvedBy.Login
+			}
+			threads = append(threads, thread)
+		}
+		if !page.PageInfo.HasNextPage {
+			return threads, nil
+		}
+		cursor = &page.PageInfo.EndCursor
+	}
+}
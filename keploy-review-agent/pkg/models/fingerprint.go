@@ -0,0 +1,72 @@
+package models
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+var (
+	quotedRegex = regexp.MustCompile("[\"'`][^\"'`]*[\"'`]")
+	numberRegex = regexp.MustCompile(`\d+`)
+	spaceRegex  = regexp.MustCompile(`\s+`)
+)
+
+// Fingerprint returns a stable identifier for an issue. It is built from the
+// file path, the rule, the normalized message and a hash of the source line
+// the issue points at, so it survives unrelated edits that shift line numbers.
+func Fingerprint(issue *Issue, content string) string {
+	h := sha256.New()
+	h.Write([]byte(issue.Path))
+	h.Write([]byte{0})
+	h.Write([]byte(ruleKey(issue)))
+	h.Write([]byte{0})
+	h.Write([]byte(NormalizeMessage(issue.Description)))
+	h.Write([]byte{0})
+	h.Write([]byte(contextHash(content, issue.Line)))
+	return hex.EncodeToString(h.Sum(nil))[:16]
+}
+
+// Occurrence returns the fingerprint of the n-th occurrence, counting from
+// zero in line order, of a finding repeated on identical lines of a file,
+// such as the same check on every `if err != nil` block. The first
+// occurrence keeps the fingerprint, so a finding only gets a new one when
+// an identical one appears above it.
+func Occurrence(fingerprint string, n int) string {
+	if n == 0 {
+		return fingerprint
+	}
+	sum := sha256.Sum256([]byte(fmt.Sprintf("%s\x00%d", fingerprint, n)))
+	return hex.EncodeToString(sum[:])[:16]
+}
+
+// ruleKey identifies the rule behind an issue, falling back to the title for
+// sources that do not report rule IDs.
+func ruleKey(issue *Issue) string {
+	if issue.RuleID != "" {
+		return issue.Source + ":" + issue.RuleID
+	}
+	return NormalizeMessage(issue.Title)
+}
+
+// NormalizeMessage lowercases a message and strips the parts that tend to
+// vary between runs or tools: quoted identifiers, numbers and whitespace.
+func NormalizeMessage(msg string) string {
+	msg = strings.ToLower(msg)
+	msg = quotedRegex.ReplaceAllString(msg, "_")
+	msg = numberRegex.ReplaceAllString(msg, "0")
+	msg = spaceRegex.ReplaceAllString(msg, " ")
+	return strings.TrimSpace(msg)
+}
+
+func contextHash(content string, line int) string {
+	lines := strings.Split(content, "\n")
+	if line < 1 || line > len(lines) {
+		return ""
+	}
+	text := spaceRegex.ReplaceAllString(strings.TrimSpace(lines[line-1]), " ")
+	sum := sha256.Sum256([]byte(text))
+	return hex.EncodeToString(sum[:8])
+}
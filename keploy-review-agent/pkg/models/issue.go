@@ -1,51 +1,77 @@
 package models
 
-type Severity string
-
-const (
-	SeverityError   Severity = "error"
-	SeverityWarning Severity = "warning"
-	SeverityInfo    Severity = "info"
+import (
+	"crypto/sha1"
+	"encoding/hex"
+	"fmt"
 )
 
-
-
-
-
-
 type Issue struct {
 	Path        string   // File path
 	Line        int      // Line number
 	Column      int      // Column number (optional)
+	EndLine     int      // Last line of the flagged range (optional)
+	EndColumn   int      // Column the flagged range ends at (optional)
 	Severity    Severity // Issue severity
+	RuleID      string   // Rule that produced the issue (e.g., "no-unused-vars", "ai/security")
+	Category    string   // Issue category (e.g., "security", "performance", "lint")
+	Confidence  float64  // Confidence in the finding between 0 and 1 (optional)
 	Title       string   // Short issue title
 	Description string   // Detailed description
 	Suggestion  string   // Suggested fix (optional)
+	Fixes       []Fix    // Machine-applicable fixes (optional)
+	Tags        []string // Classification tags such as CWE or OWASP identifiers
+	DocURL      string   // Link to the rule or advisory documentation (optional)
+	CVSS        float64  // CVSS score of vulnerability findings (optional)
+	NewDep      bool     // the vulnerable dependency is added by the change
 	Source      string   // Source of the issue (e.g., "golangci-lint", "llm")
+	Sources     []string // All sources that reported the issue after deduplication
+	Fingerprint string   // Stable identifier, see Fingerprint
 }
 
-type AffectedVersion struct {
-    Introduced string
-    Fixed      string
+// Fix is a structured fix made of one or more edits to the issue's file.
+type Fix struct {
+	Description string
+	Edits       []TextEdit
 }
 
+// TextEdit replaces the text between the start and end positions (1-based,
+// end exclusive) with NewText.
+type TextEdit struct {
+	StartLine   int
+	StartColumn int
+	EndLine     int
+	EndColumn   int
+	NewText     string
+}
 
-
-
-
-
-
-
+type AffectedVersion struct {
+	Introduced string
+	Fixed      string
+}
 
 type File struct {
 	Path    string // File path
 	Content string // File content
+	Patch   string // Unified diff of the change, empty when unavailable
+	SHA     string // Git blob SHA of the content, empty when unknown
+}
+
+// BlobSHA returns the Git blob SHA of the file, computing it from the
+// content when the provider did not report it.
+func (f *File) BlobSHA() string {
+	if f.SHA != "" {
+		return f.SHA
+	}
+	sum := sha1.Sum([]byte(fmt.Sprintf("blob %d\x00%s", len(f.Content), f.Content)))
+	return hex.EncodeToString(sum[:])
 }
 
 type ReviewComment struct {
-	Path      string // File path
-	Line      int    // Line number
-	Body      string // Comment body
-	CommitID  string // Commit ID
-	Position  int    // Position in the diff
+	Path        string // File path
+	Line        int    // Line number
+	Body        string // Comment body
+	CommitID    string // Commit ID
+	Position    int    // Position in the diff
+	Fingerprint string // Fingerprint of the issue the comment reports
 }
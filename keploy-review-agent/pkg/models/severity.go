@@ -0,0 +1,103 @@
+package models
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Severity is an ordered issue severity; a higher value is more severe. The
+// zero value is not a valid severity and is treated as "no threshold" when
+// used as a minimum.
+type Severity int
+
+const (
+	SeverityHint Severity = iota + 1
+	SeverityInfo
+	SeverityWarning
+	SeverityError
+	SeverityCritical
+)
+
+var severityNames = map[Severity]string{
+	SeverityHint:     "hint",
+	SeverityInfo:     "info",
+	SeverityWarning:  "warning",
+	SeverityError:    "error",
+	SeverityCritical: "critical",
+}
+
+// Severities lists all severities from most to least severe.
+var Severities = []Severity{
+	SeverityCritical,
+	SeverityError,
+	SeverityWarning,
+	SeverityInfo,
+	SeverityHint,
+}
+
+func (s Severity) String() string {
+	if name, ok := severityNames[s]; ok {
+		return name
+	}
+	return "unknown"
+}
+
+// ParseSeverity parses a severity name as used in configuration. Matching is
+// case-insensitive and accepts a few common aliases.
+func ParseSeverity(s string) (Severity, error) {
+	switch strings.ToLower(strings.TrimSpace(s)) {
+	case "critical", "blocker", "fatal":
+		return SeverityCritical, nil
+	case "error", "high":
+		return SeverityError, nil
+	case "warning", "warn", "medium":
+		return SeverityWarning, nil
+	case "info", "low":
+		return SeverityInfo, nil
+	case "hint", "note":
+		return SeverityHint, nil
+	}
+	return 0, fmt.Errorf("unknown severity %q (expected one of critical, error, warning, info, hint)", s)
+}
+
+// AtLeast reports whether s is at least as severe as min. Every severity
+// satisfies the zero threshold.
+func (s Severity) AtLeast(min Severity) bool {
+	return s >= min
+}
+
+func (s Severity) MarshalText() ([]byte, error) {
+	return []byte(s.String()), nil
+}
+
+func (s *Severity) UnmarshalText(text []byte) error {
+	parsed, err := ParseSeverity(string(text))
+	if err != nil {
+		return err
+	}
+	*s = parsed
+	return nil
+}
+
+// FilterBySeverity returns the issues that are at least as severe as min.
+func FilterBySeverity(issues []*Issue, min Severity) []*Issue {
+	var filtered []*Issue
+	for _, issue := range issues {
+		if issue.Severity.AtLeast(min) {
+			filtered = append(filtered, issue)
+		}
+	}
+	return filtered
+}
+
+// HighestSeverity returns the most severe level among the issues, or zero if
+// there are none.
+func HighestSeverity(issues []*Issue) Severity {
+	var highest Severity
+	for _, issue := range issues {
+		if issue.Severity > highest {
+			highest = issue.Severity
+		}
+	}
+	return highest
+}
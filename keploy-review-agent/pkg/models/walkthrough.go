@@ -0,0 +1,14 @@
+package models
+
+// Walkthrough is a summary of a pull request for reviewers.
+type Walkthrough struct {
+	Overview  string        `json:"overview"`
+	Files     []FileSummary `json:"files"`
+	Risks     []string      `json:"risks"`
+	TestFocus []string      `json:"test_focus"`
+}
+
+type FileSummary struct {
+	Path    string `json:"path"`
+	Summary string `json:"summary"`
+}